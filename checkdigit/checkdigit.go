@@ -0,0 +1,178 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/ModulusCheckDigit.java?view=log
+ */
+package checkdigit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+var (
+	ErrMissingCode       = errors.New("checkdigit: code is missing")
+	ErrInvalidCharacter  = errors.New("checkdigit: invalid character")
+	ErrZeroSum           = errors.New("checkdigit: invalid code, sum is zero")
+	ErrInvalidCodeLength = errors.New("checkdigit: invalid code length")
+)
+
+/**
+ * CheckDigitError reports why a check digit couldn't be calculated.
+ * Segment is the offending character, or the code for ErrZeroSum and
+ * ErrInvalidCodeLength, and Offset its byte offset in the code.
+ */
+type CheckDigitError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *CheckDigitError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *CheckDigitError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * Check Digit calculation and validation.
+ *
+ * The logic for validating check digits has previously been
+ * embedded within the logic for specific code validation, which
+ * includes other validations such as verifying the format
+ * or length of a code. CheckDigit provides for separating out
+ * the check digit calculation logic enabling it to be more easily
+ * tested and reused.
+ */
+type CheckDigit interface {
+	/**
+	 * Calculates the Check Digit for a code.
+	 * @param code The code to calculate the Check Digit for.
+	 * The string must not include the check digit
+	 * @return The calculated Check Digit, and nil, otherwise a
+	 * *CheckDigitError.
+	 */
+	Calculate(code string) (string, error)
+
+	/**
+	 * Validates the check digit for the code.
+	 * @param code The code to validate, the string must include the
+	 * check digit.
+	 * @return true if the check digit is valid, otherwise false.
+	 */
+	IsValid(code string) bool
+}
+
+// weighter is implemented by the modulus check digit routines, which
+// differ in the weighting of each character and the characters allowed.
+type weighter interface {
+	modulus() int
+
+	// weightedValue calculates the weighted value of a character in the
+	// code at the specified position. leftPos counts from 1 at the left
+	// of the code and rightPos from 1 at the check digit.
+	weightedValue(charValue, leftPos, rightPos int) int
+
+	// toInt converts the character at the specified position to an
+	// integer value, and reports whether it is allowed there.
+	toInt(c byte, leftPos, rightPos int) (int, bool)
+
+	// toCheckDigit converts an integer value to a check digit.
+	toCheckDigit(charValue int) string
+}
+
+// calculate is the Calculate method of the modulus check digit routines.
+func calculate(w weighter, code string) (string, error) {
+	if code == "" {
+		return "", &CheckDigitError{Err: ErrMissingCode}
+	}
+	modulusResult, err := calculateModulus(w, code, false)
+	if err != nil {
+		return "", err
+	}
+	charValue := (w.modulus() - modulusResult) % w.modulus()
+	return w.toCheckDigit(charValue), nil
+}
+
+// isValid is the IsValid method of the modulus check digit routines.
+func isValid(w weighter, code string) bool {
+	if code == "" {
+		return false
+	}
+	modulusResult, err := calculateModulus(w, code, true)
+	return err == nil && modulusResult == 0
+}
+
+/*
+ * calculateModulus calculates the modulus for a code, which includes
+ * the check digit if includesCheckDigit is set.
+ */
+func calculateModulus(w weighter, code string, includesCheckDigit bool) (int, *CheckDigitError) {
+	total := 0
+	lth := len(code)
+	if !includesCheckDigit {
+		lth++
+	}
+	for i := 0; i < len(code); i++ {
+		leftPos := i + 1
+		rightPos := lth - i
+		charValue, ok := w.toInt(code[i], leftPos, rightPos)
+		if !ok {
+			return 0, invalidCharacter(code, i)
+		}
+		total += w.weightedValue(charValue, leftPos, rightPos)
+	}
+	if total == 0 {
+		return 0, &CheckDigitError{Err: ErrZeroSum, Segment: code}
+	}
+	return total % w.modulus(), nil
+}
+
+// invalidCharacter reports the character that the byte at offset i of
+// code belongs to.
+func invalidCharacter(code string, i int) *CheckDigitError {
+	for i > 0 && !utf8.RuneStart(code[i]) {
+		i--
+	}
+	_, size := utf8.DecodeRuneInString(code[i:])
+	return &CheckDigitError{Err: ErrInvalidCharacter, Segment: code[i : i+size], Offset: i}
+}
+
+// toDigit converts a decimal digit to its value.
+func toDigit(c byte) (int, bool) {
+	if c < '0' || c > '9' {
+		return 0, false
+	}
+	return int(c - '0'), true
+}
+
+// toDigitString converts a check digit value of 0 to 9 to a string.
+func toDigitString(charValue int) string {
+	return strconv.Itoa(charValue)
+}
+
+// sumDigits adds together the individual digits in a number.
+func sumDigits(number int) int {
+	total := 0
+	for todo := number; todo > 0; todo /= 10 {
+		total += todo % 10
+	}
+	return total
+}
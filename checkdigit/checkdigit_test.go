@@ -0,0 +1,313 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/checkdigit/AbstractCheckDigitTest.java?view=log
+ */
+package checkdigit
+
+import (
+	"errors"
+	"testing"
+)
+
+/**
+ * Runs the tests of AbstractCheckDigitTest against a routine: the valid
+ * codes must validate and have their check digit calculated, and
+ * changing the check digit or using invalid characters must fail.
+ */
+func testCheckDigit(t *testing.T, routine CheckDigit, checkDigits string, valid, invalid []string) {
+	for _, code := range valid {
+		if !routine.IsValid(code) {
+			t.Errorf("expected valid code: %s", code)
+		}
+		check := code[len(code)-1:]
+		got, err := routine.Calculate(code[:len(code)-1])
+		if err != nil || got != check {
+			t.Errorf("Calculate(%s): got %q, %v, want %q", code[:len(code)-1], got, err, check)
+		}
+
+		// every other check digit is invalid
+		for i := 0; i < len(checkDigits); i++ {
+			if other := checkDigits[i : i+1]; other != check {
+				if routine.IsValid(code[:len(code)-1] + other) {
+					t.Errorf("expected invalid code: %s", code[:len(code)-1]+other)
+				}
+			}
+		}
+	}
+
+	for _, code := range invalid {
+		if routine.IsValid(code) {
+			t.Errorf("expected invalid code: %s", code)
+		}
+		if _, err := routine.Calculate(code); !errors.Is(err, ErrInvalidCharacter) {
+			t.Errorf("Calculate(%s): expected ErrInvalidCharacter, got %v", code, err)
+		}
+	}
+
+	// missing code
+	if routine.IsValid("") {
+		t.Errorf("expected empty code to be invalid")
+	}
+	if _, err := routine.Calculate(""); !errors.Is(err, ErrMissingCode) {
+		t.Errorf("Calculate(\"\"): expected ErrMissingCode, got %v", err)
+	}
+}
+
+// testZeroSum checks modulus routines reject a code that sums to zero.
+func testZeroSum(t *testing.T, routine CheckDigit) {
+	if routine.IsValid("0000000000") {
+		t.Errorf("expected zero sum to be invalid")
+	}
+	if _, err := routine.Calculate("000000000"); !errors.Is(err, ErrZeroSum) {
+		t.Errorf("expected ErrZeroSum, got %v", err)
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	valid := []string{
+		"4417123456789113", // VISA
+		"4222222222222",    // short VISA
+		"378282246310005",  // AMEX
+		"5105105105105100", // Mastercard
+		"6011000990139424", // Discover
+		"30569309025904",   // Diners
+		"490154203237518",  // IMEI
+	}
+	invalid := []string{"4417a23456789113", "4417 123456789113", "4417-1234-5678-9113"}
+	testCheckDigit(t, LUHN_CHECK_DIGIT, "0123456789", valid, invalid)
+	testZeroSum(t, LUHN_CHECK_DIGIT)
+
+	// Luhn is a modulus 10 routine weighting with 1 and 2 from the right
+	testCheckDigit(t, NewModulusTenCheckDigit([]int{1, 2}, true, true), "0123456789", valid, invalid)
+}
+
+func TestModulusTen(t *testing.T) {
+	// EAN-13
+	ean := NewModulusTenCheckDigit([]int{1, 3}, true, false)
+	valid := []string{
+		"9780072129519",
+		"9780764558313",
+		"4025515373438",
+		"0095673400332",
+	}
+	invalid := []string{"978007212951X", "97800721295 9"}
+	testCheckDigit(t, ean, "0123456789", valid, invalid)
+	testZeroSum(t, ean)
+
+	// the check digit has to be numeric
+	if ean.IsValid("978007212951X") {
+		t.Errorf("expected non-numeric check digit to be invalid")
+	}
+
+	// weights from the left, which suit EAN-13 as it has an odd length
+	left := NewModulusTenCheckDigit([]int{1, 3}, false, false)
+	if got, err := left.Calculate("978007212951"); err != nil || got != "9" {
+		t.Errorf("Calculate: got %q, %v", got, err)
+	}
+}
+
+func TestModulusEleven(t *testing.T) {
+	// ISBN-10
+	isbn := NewModulusElevenCheckDigit([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, true)
+	valid := []string{
+		"1930110995",
+		"020163385X",
+		"1932394354",
+		"1590596277",
+		"0306406152",
+	}
+	invalid := []string{"193011099X5", "X930110995", "02016338x5"}
+	testCheckDigit(t, isbn, "0123456789X", valid, invalid)
+	testZeroSum(t, isbn)
+
+	if isbn.IsValid("020163385x") {
+		t.Errorf("expected lower case check digit to be invalid")
+	}
+}
+
+/**
+ * Test the EAN-13, ISBN-10 and ISSN routines.
+ */
+func TestStandardRoutines(t *testing.T) {
+	ean := []string{
+		"9780072129519",
+		"9780764558313",
+		"4025515373438",
+		"0095673400332",
+	}
+	testCheckDigit(t, EAN13_CHECK_DIGIT, "0123456789", ean, []string{"978007212951X", "97800721295 9"})
+	testZeroSum(t, EAN13_CHECK_DIGIT)
+
+	isbn := []string{
+		"1930110995",
+		"020163385X",
+		"1932394354",
+		"1590596277",
+		"0306406152",
+	}
+	testCheckDigit(t, ISBN10_CHECK_DIGIT, "0123456789X", isbn, []string{"193011099X5", "X930110995", "02016338x5"})
+	testZeroSum(t, ISBN10_CHECK_DIGIT)
+
+	issn := []string{
+		"03178471",
+		"1050124X",
+		"15625230",
+		"15624250",
+		"00284793",
+		"2434561X",
+	}
+	testCheckDigit(t, ISSN_CHECK_DIGIT, "0123456789X", issn, []string{"0317847X1", "0317-8471"})
+}
+
+/**
+ * Test that empty weights mean weight 1 for every position.
+ */
+func TestModulusEmptyWeights(t *testing.T) {
+	for _, weights := range [][]int{nil, {}} {
+		ten := NewModulusTenCheckDigit(weights, true, false)
+		if got, err := ten.Calculate("1234"); err != nil || got != "0" {
+			t.Errorf("modulus 10 Calculate(%v): got %q, %v", weights, got, err)
+		}
+		eleven := NewModulusElevenCheckDigit(weights, false)
+		if got, err := eleven.Calculate("1234"); err != nil || got != "1" {
+			t.Errorf("modulus 11 Calculate(%v): got %q, %v", weights, got, err)
+		}
+	}
+}
+
+func TestVerhoeff(t *testing.T) {
+	valid := []string{
+		"15",
+		"2363",
+		"1428570",
+		"1234567890120",
+		"123456789014",
+		"8473643095483728456789270",
+		"40128888888818814",
+	}
+	invalid := []string{"1a", "142857+0", "12345678901Ⅸ"}
+	testCheckDigit(t, VERHOEFF_CHECK_DIGIT, "0123456789", valid, invalid)
+}
+
+func TestDamm(t *testing.T) {
+	valid := []string{
+		"5724",
+		"112946",
+		"1234567894",
+		"8473643095483728456789271",
+		"40128888888818816",
+	}
+	invalid := []string{"57a4", " 5724", "1234567894 "}
+	testCheckDigit(t, DAMM_CHECK_DIGIT, "0123456789", valid, invalid)
+
+	// zero sum is valid
+	if !DAMM_CHECK_DIGIT.IsValid("0000") {
+		t.Errorf("expected 0000 to be valid")
+	}
+}
+
+func TestIBAN(t *testing.T) {
+	valid := []string{
+		"AD1200012030200359100100",
+		"AT611904300234573201",
+		"BE62510007547061",
+		"BE68539007547034",
+		"CH3900700115201849173",
+		"DE89370400440532013000",
+		"FR1420041010050500013M02606",
+		"GB29NWBK60161331926819",
+		"IT60X0542811101000000123456",
+		"MT84MALT011000012345MTLCAST001S",
+		"NL91ABNA0417164300",
+		"NO9386011117947",
+		"SE4550000000058398257466",
+		"gb29nwbk60161331926819", // case is for the format to check
+	}
+	for _, code := range valid {
+		if !IBAN_CHECK_DIGIT.IsValid(code) {
+			t.Errorf("expected valid code: %s", code)
+		}
+		got, err := IBAN_CHECK_DIGIT.Calculate(code)
+		if err != nil || got != code[2:4] {
+			t.Errorf("Calculate(%s): got %q, %v, want %q", code, got, err, code[2:4])
+		}
+		if IBAN_CHECK_DIGIT.IsValid(code[:2] + "00" + code[4:]) {
+			t.Errorf("expected invalid code: %s", code[:2]+"00"+code[4:])
+		}
+	}
+
+	invalid := []string{
+		"",
+		"AD12",
+		"AD0000012030200359100100", // check digits 00
+		"AD0100012030200359100100", // check digits 01
+		"AD9900012030200359100100", // check digits 99
+		"GB29NWBK60161331926818",
+		"GB29 NWBK 6016 1331 9268 19",
+	}
+	for _, code := range invalid {
+		if IBAN_CHECK_DIGIT.IsValid(code) {
+			t.Errorf("expected invalid code: %s", code)
+		}
+	}
+
+	if _, err := IBAN_CHECK_DIGIT.Calculate(""); !errors.Is(err, ErrMissingCode) {
+		t.Errorf("expected ErrMissingCode, got %v", err)
+	}
+	if _, err := IBAN_CHECK_DIGIT.Calculate("GB29"); !errors.Is(err, ErrInvalidCodeLength) {
+		t.Errorf("expected ErrInvalidCodeLength, got %v", err)
+	}
+	if got, err := IBAN_CHECK_DIGIT.Calculate("XY001234567890"); err != nil || got != "33" {
+		t.Errorf("Calculate: got %q, %v", got, err)
+	}
+}
+
+/**
+ * Test the errors report the offending character and its offset.
+ */
+func TestCheckDigitError(t *testing.T) {
+	tests := []struct {
+		routine CheckDigit
+		code    string
+		err     error
+		segment string
+		offset  int
+	}{
+		{LUHN_CHECK_DIGIT, "", ErrMissingCode, "", 0},
+		{LUHN_CHECK_DIGIT, "41a7", ErrInvalidCharacter, "a", 2},
+		{LUHN_CHECK_DIGIT, "000", ErrZeroSum, "000", 0},
+		{NewModulusTenCheckDigit([]int{1, 3}, true, false), "97800é", ErrInvalidCharacter, "é", 5},
+		{NewModulusElevenCheckDigit([]int{1, 2, 3}, true), "12X", ErrInvalidCharacter, "X", 2},
+		{VERHOEFF_CHECK_DIGIT, "1é2", ErrInvalidCharacter, "é", 1},
+		{DAMM_CHECK_DIGIT, "12€3", ErrInvalidCharacter, "€", 2},
+		{IBAN_CHECK_DIGIT, "GB29NWBK-60161331926819", ErrInvalidCharacter, "-", 8},
+		{IBAN_CHECK_DIGIT, "G+29NWBK60161331926819", ErrInvalidCharacter, "+", 1},
+		{IBAN_CHECK_DIGIT, "GB2", ErrInvalidCodeLength, "GB2", 0},
+	}
+	for _, tt := range tests {
+		_, err := tt.routine.Calculate(tt.code)
+		var cerr *CheckDigitError
+		if !errors.As(err, &cerr) {
+			t.Errorf("Calculate(%q): expected *CheckDigitError, got %v", tt.code, err)
+			continue
+		}
+		if cerr.Err != tt.err || cerr.Segment != tt.segment || cerr.Offset != tt.offset {
+			t.Errorf("Calculate(%q): got %v, %q, %d, want %v, %q, %d",
+				tt.code, cerr.Err, cerr.Segment, cerr.Offset, tt.err, tt.segment, tt.offset)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 Doug Sparling. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkdigit
+
+/**
+ * Damm Check Digit calculation/validation.
+ *
+ * Check digit calculation for numeric codes using a totally
+ * anti-symmetric quasigroup of order 10. Like Verhoeff it detects all
+ * single digit errors and all adjacent transpositions, with a single
+ * table.
+ *
+ * See https://en.wikipedia.org/wiki/Damm_algorithm for more details.
+ */
+type DammCheckDigit struct{}
+
+// Singleton Damm Check Digit instance
+var DAMM_CHECK_DIGIT CheckDigit = DammCheckDigit{}
+
+// The quasigroup operation table, from Damm's 2004 dissertation
+var dammTable = [10][10]int{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+/**
+ * Calculate a Damm Check Digit for a code.
+ * @param code The code to calculate the Check Digit for
+ * @return The calculated Check Digit, and nil, otherwise a
+ * *CheckDigitError.
+ */
+func (DammCheckDigit) Calculate(code string) (string, error) {
+	if code == "" {
+		return "", &CheckDigitError{Err: ErrMissingCode}
+	}
+	interim, err := dammInterim(code)
+	if err != nil {
+		return "", err
+	}
+	return toDigitString(interim), nil
+}
+
+/**
+ * Validate the Damm Check Digit for a code.
+ * @param code The code to validate
+ * @return true if the check digit is valid, otherwise
+ * false
+ */
+func (DammCheckDigit) IsValid(code string) bool {
+	if code == "" {
+		return false
+	}
+	interim, err := dammInterim(code)
+	return err == nil && interim == 0
+}
+
+// dammInterim runs the digits of code through the table, from the left.
+func dammInterim(code string) (int, *CheckDigitError) {
+	interim := 0
+	for i := 0; i < len(code); i++ {
+		num, ok := toDigit(code[i])
+		if !ok {
+			return 0, invalidCharacter(code, i)
+		}
+		interim = dammTable[interim][num]
+	}
+	return interim, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/IBANCheckDigit.java?view=log
+ */
+package checkdigit
+
+/**
+ * IBAN (International Bank Account Number) Check Digit
+ * calculation/validation.
+ *
+ * This routine is based on the ISO 7064 Mod 97,10 check digit
+ * calculation routine.
+ *
+ * The two check digit characters in a IBAN number are the third and
+ * fourth characters in the code. For check digit calculation/validation
+ * the first four characters are moved to the end of the code. So
+ * CCDDnnnnnnn becomes nnnnnnnCCDD (where CC is the country code and DD
+ * is the check digit). For check digit calculation the check digit
+ * value should be set to zero (i.e. CC00nnnnnnn in this example).
+ *
+ * Note: the class does not check the format of the IBAN number, only
+ * the check digits.
+ *
+ * For further information see
+ * https://en.wikipedia.org/wiki/International_Bank_Account_Number.
+ */
+type IBANCheckDigit struct{}
+
+// Singleton IBAN Number Check Digit instance
+var IBAN_CHECK_DIGIT CheckDigit = IBANCheckDigit{}
+
+const (
+	ibanMinCodeLen = 5
+	ibanMax        = 999999999
+	ibanModulus    = 97
+)
+
+/**
+ * Calculate the Check Digit for an IBAN code.
+ *
+ * Note: The check digit is the third and fourth characters and is set
+ * to the value "00".
+ * @param code The code to calculate the Check Digit for, including
+ * the check digit positions, whose value is ignored
+ * @return The calculated Check Digit as 2 numeric decimal characters,
+ * e.g. "42", and nil, otherwise a *CheckDigitError.
+ */
+func (IBANCheckDigit) Calculate(code string) (string, error) {
+	if code == "" {
+		return "", &CheckDigitError{Err: ErrMissingCode}
+	}
+	if len(code) < ibanMinCodeLen {
+		return "", &CheckDigitError{Err: ErrInvalidCodeLength, Segment: code}
+	}
+	modulusResult, err := ibanModulusResult(code[:2]+"00"+code[4:], code)
+	if err != nil {
+		return "", err
+	}
+	charValue := 98 - modulusResult
+	return string([]byte{byte('0' + charValue/10), byte('0' + charValue%10)}), nil
+}
+
+/**
+ * Validate the check digit of an IBAN code.
+ * @param code The code to validate
+ * @return true if the check digit is valid, otherwise
+ * false
+ */
+func (IBANCheckDigit) IsValid(code string) bool {
+	if len(code) < ibanMinCodeLen {
+		return false
+	}
+	switch code[2:4] {
+	case "00", "01", "99":
+		return false
+	}
+	modulusResult, err := ibanModulusResult(code, code)
+	return err == nil && modulusResult == 1
+}
+
+/*
+ * ibanModulusResult calculates the modulus of a code after moving its
+ * first four characters to the end. Letters count as 10 to 35. Errors
+ * are reported against original, which differs from code only in the
+ * check digits.
+ */
+func ibanModulusResult(code, original string) (int, *CheckDigitError) {
+	total := 0
+	for n := 0; n < len(code); n++ {
+		i := (n + 4) % len(code)
+		var charValue int
+		switch c := code[i]; {
+		case '0' <= c && c <= '9':
+			charValue = int(c - '0')
+		case 'A' <= c && c <= 'Z':
+			charValue = int(c-'A') + 10
+		case 'a' <= c && c <= 'z':
+			charValue = int(c-'a') + 10
+		default:
+			return 0, invalidCharacter(original, i)
+		}
+		if charValue > 9 {
+			total = total*100 + charValue
+		} else {
+			total = total*10 + charValue
+		}
+		if total > ibanMax {
+			total %= ibanModulus
+		}
+	}
+	return total % ibanModulus, nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/LuhnCheckDigit.java?view=log
+ */
+package checkdigit
+
+/**
+ * Modulus 10 Luhn Check Digit calculation/validation.
+ *
+ * Luhn check digits are used, for example, by:
+ *  - Credit Card Numbers
+ *  - IMEI Numbers - International Mobile Equipment Identity Numbers
+ *
+ * Check digit calculation is based on modulus 10 with digits in
+ * an odd position (from right to left) being weighted 1 and even
+ * position digits being weighted 2 (weighted values greater than 9
+ * have 9 subtracted).
+ *
+ * See https://en.wikipedia.org/wiki/Luhn_algorithm for more details.
+ */
+type LuhnCheckDigit struct{}
+
+// Singleton Luhn Check Digit instance
+var LUHN_CHECK_DIGIT CheckDigit = LuhnCheckDigit{}
+
+// weighting given to digits depending on their right position
+var luhnPositionWeight = [2]int{2, 1}
+
+func (l LuhnCheckDigit) Calculate(code string) (string, error) {
+	return calculate(l, code)
+}
+
+func (l LuhnCheckDigit) IsValid(code string) bool {
+	return isValid(l, code)
+}
+
+func (LuhnCheckDigit) modulus() int {
+	return 10
+}
+
+/*
+ * weightedValue calculates the weighted value of a character in the
+ * code at a specified position. For Luhn (from right to left) odd
+ * digits are weighted with a factor of one and even digits with a
+ * factor of two. Weighted values > 9, have 9 subtracted.
+ */
+func (LuhnCheckDigit) weightedValue(charValue, leftPos, rightPos int) int {
+	weightedValue := charValue * luhnPositionWeight[rightPos%2]
+	if weightedValue > 9 {
+		return weightedValue - 9
+	}
+	return weightedValue
+}
+
+func (LuhnCheckDigit) toInt(c byte, leftPos, rightPos int) (int, bool) {
+	return toDigit(c)
+}
+
+func (LuhnCheckDigit) toCheckDigit(charValue int) string {
+	return toDigitString(charValue)
+}
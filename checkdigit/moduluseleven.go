@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/ISBN10CheckDigit.java?view=log
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/ISSNCheckDigit.java?view=log
+ */
+package checkdigit
+
+/**
+ * General Modulus 11 Check Digit calculation/validation.
+ *
+ * Each digit is multiplied by a weighting factor selected from the
+ * positionWeight array based on its position, either left-to-right
+ * (when useRightPos=false) or right-to-left (when useRightPos=true),
+ * and the weighted values are totalled. The check digit is the value
+ * that makes the total a multiple of 11. A check digit of 10 is
+ * written as X.
+ *
+ * For the check digit to validate, the weight at the position of the
+ * check digit must be 1; weights from the right start with it. The
+ * weights of ISBN-10, for example, are the positions from the right:
+ *
+ *	NewModulusElevenCheckDigit([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, true)
+ *
+ * Only the digits 0 to 9 are allowed, and X as the check digit.
+ */
+type ModulusElevenCheckDigit struct {
+	positionWeight []int
+	useRightPos    bool
+}
+
+/**
+ * Singleton ISBN-10 Check Digit instance: the digits of the 10
+ * character code are weighted with their position from the right.
+ */
+var ISBN10_CHECK_DIGIT CheckDigit = NewModulusElevenCheckDigit([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, true)
+
+/**
+ * Singleton ISSN Check Digit instance: the digits of the 8 character
+ * code are weighted with their position from the right.
+ */
+var ISSN_CHECK_DIGIT CheckDigit = NewModulusElevenCheckDigit([]int{1, 2, 3, 4, 5, 6, 7, 8}, true)
+
+/**
+ * Construct a modulus 11 Check Digit routine with the specified
+ * weighting, indicating whether its from the left or right of the code.
+ * @param positionWeight the weighted values to apply based on the
+ * character position, if empty every position has weight 1
+ * @param useRightPos true if use positionWeights from right to left
+ * @return the check digit routine
+ */
+func NewModulusElevenCheckDigit(positionWeight []int, useRightPos bool) *ModulusElevenCheckDigit {
+	m := &ModulusElevenCheckDigit{
+		positionWeight: make([]int, len(positionWeight)),
+		useRightPos:    useRightPos,
+	}
+	copy(m.positionWeight, positionWeight)
+	if len(m.positionWeight) == 0 {
+		m.positionWeight = []int{1}
+	}
+	return m
+}
+
+func (m *ModulusElevenCheckDigit) Calculate(code string) (string, error) {
+	return calculate(m, code)
+}
+
+func (m *ModulusElevenCheckDigit) IsValid(code string) bool {
+	return isValid(m, code)
+}
+
+func (m *ModulusElevenCheckDigit) modulus() int {
+	return 11
+}
+
+func (m *ModulusElevenCheckDigit) weightedValue(charValue, leftPos, rightPos int) int {
+	pos := leftPos
+	if m.useRightPos {
+		pos = rightPos
+	}
+	return charValue * m.positionWeight[(pos-1)%len(m.positionWeight)]
+}
+
+// toInt allows X, for 10, as the check digit.
+func (m *ModulusElevenCheckDigit) toInt(c byte, leftPos, rightPos int) (int, bool) {
+	if rightPos == 1 && c == 'X' {
+		return 10, true
+	}
+	return toDigit(c)
+}
+
+func (m *ModulusElevenCheckDigit) toCheckDigit(charValue int) string {
+	if charValue == 10 {
+		return "X"
+	}
+	return toDigitString(charValue)
+}
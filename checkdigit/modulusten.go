@@ -0,0 +1,148 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/ModulusTenCheckDigit.java?view=log
+ */
+package checkdigit
+
+/**
+ * General Modulus 10 Check Digit calculation/validation.
+ *
+ * How it Works
+ *
+ * This implementation calculates/validates the check digit in the
+ * following way:
+ *  - Converting each character to an integer value; only the digits
+ *    0 to 9 are allowed
+ *  - Multiplying the character's integer value by a weighting factor.
+ *    The weighting factor is selected from the positionWeight array
+ *    based on its position. The positionWeight values are used either
+ *    left-to-right (when useRightPos=false) or right-to-left (when
+ *    useRightPos=true).
+ *  - If sumWeightedDigits=true, the weighted value is re-calculated by
+ *    summing its digits.
+ *  - The weighted values of each character are totalled.
+ *  - The total modulo 10 will be zero for a code with a valid Check
+ *    Digit.
+ *
+ * Limitations
+ *
+ * This implementation has the following limitations:
+ *  - It assumes the last character in the code is the Check Digit and
+ *    validates that it is a numeric character. The weight at its
+ *    position has to be 1.
+ *  - The only limitation on valid characters are those that
+ *    Calculate and IsValid accept, the digits 0 to 9. Additional
+ *    checks should be performed to validate the format and length of
+ *    the code.
+ *
+ * Example Usage
+ *
+ * EAN-13 and ISBN-13 weight digits from the right with 1 and 3:
+ *
+ *	NewModulusTenCheckDigit([]int{1, 3}, true, false)
+ *
+ * and Luhn (as LUHN_CHECK_DIGIT) with 1 and 2, summing the digits of
+ * the weighted values:
+ *
+ *	NewModulusTenCheckDigit([]int{1, 2}, true, true)
+ */
+type ModulusTenCheckDigit struct {
+	positionWeight    []int
+	useRightPos       bool
+	sumWeightedDigits bool
+}
+
+/**
+ * Singleton EAN-13 Check Digit instance, for EAN-13 (including
+ * ISBN-13) and UPC-A codes: digits are weighted 1 and 3 from the
+ * right.
+ */
+var EAN13_CHECK_DIGIT CheckDigit = NewModulusTenCheckDigit([]int{1, 3}, true, false)
+
+/**
+ * Construct a modulus 10 Check Digit routine with the specified
+ * weighting, indicating whether its from the left or right of the code
+ * and whether the weighted digits should be summed.
+ * @param positionWeight the weighted values to apply based on the
+ * character position, if empty every position has weight 1
+ * @param useRightPos true if use positionWeights from right to left
+ * @param sumWeightedDigits true if sum the digits of the weighted value
+ * @return the check digit routine
+ */
+func NewModulusTenCheckDigit(positionWeight []int, useRightPos, sumWeightedDigits bool) *ModulusTenCheckDigit {
+	m := &ModulusTenCheckDigit{
+		positionWeight:    make([]int, len(positionWeight)),
+		useRightPos:       useRightPos,
+		sumWeightedDigits: sumWeightedDigits,
+	}
+	copy(m.positionWeight, positionWeight)
+	if len(m.positionWeight) == 0 {
+		m.positionWeight = []int{1}
+	}
+	return m
+}
+
+func (m *ModulusTenCheckDigit) Calculate(code string) (string, error) {
+	return calculate(m, code)
+}
+
+/**
+ * Validate a modulus check digit for a code.
+ *
+ * Note: assumes last digit is the check digit
+ * @param code The code to validate
+ * @return true if the check digit is valid, otherwise false
+ */
+func (m *ModulusTenCheckDigit) IsValid(code string) bool {
+	if code == "" {
+		return false
+	}
+	if _, ok := toDigit(code[len(code)-1]); !ok {
+		return false
+	}
+	return isValid(m, code)
+}
+
+func (m *ModulusTenCheckDigit) modulus() int {
+	return 10
+}
+
+/*
+ * weightedValue calculates the weighted value of a character in the
+ * code at a specified position, using the weights from the left or
+ * right as set by useRightPos. If sumWeightedDigits is set the digits
+ * of the weighted value are summed.
+ */
+func (m *ModulusTenCheckDigit) weightedValue(charValue, leftPos, rightPos int) int {
+	pos := leftPos
+	if m.useRightPos {
+		pos = rightPos
+	}
+	weightedValue := charValue * m.positionWeight[(pos-1)%len(m.positionWeight)]
+	if m.sumWeightedDigits {
+		weightedValue = sumDigits(weightedValue)
+	}
+	return weightedValue
+}
+
+func (m *ModulusTenCheckDigit) toInt(c byte, leftPos, rightPos int) (int, bool) {
+	return toDigit(c)
+}
+
+func (m *ModulusTenCheckDigit) toCheckDigit(charValue int) string {
+	return toDigitString(charValue)
+}
@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/checkdigit/VerhoeffCheckDigit.java?view=log
+ */
+package checkdigit
+
+/**
+ * Verhoeff (Dihedral) Check Digit calculation/validation.
+ *
+ * Check digit calculation for numeric codes using a Dihedral Group of
+ * order 10.
+ *
+ * See https://en.wikipedia.org/wiki/Verhoeff_algorithm for more details.
+ */
+type VerhoeffCheckDigit struct{}
+
+// Singleton Verhoeff Check Digit instance
+var VERHOEFF_CHECK_DIGIT CheckDigit = VerhoeffCheckDigit{}
+
+// D - multiplication table
+var verhoeffDTable = [10][10]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+	{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+	{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+	{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+	{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+	{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+	{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+	{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+	{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+}
+
+// P - permutation table
+var verhoeffPTable = [8][10]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+	{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+	{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+	{9, 4, 5, 3, 1, 2, 8, 7, 6, 0},
+	{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+	{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+	{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+}
+
+// inv: inverse table
+var verhoeffInvTable = [10]int{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+
+/**
+ * Calculate a Verhoeff Check Digit for a code.
+ * @param code The code to calculate the Check Digit for
+ * @return The calculated Check Digit, and nil, otherwise a
+ * *CheckDigitError.
+ */
+func (VerhoeffCheckDigit) Calculate(code string) (string, error) {
+	if code == "" {
+		return "", &CheckDigitError{Err: ErrMissingCode}
+	}
+	checksum, err := verhoeffChecksum(code, false)
+	if err != nil {
+		return "", err
+	}
+	return toDigitString(verhoeffInvTable[checksum]), nil
+}
+
+/**
+ * Validate the Verhoeff Check Digit for a code.
+ * @param code The code to validate
+ * @return true if the check digit is valid, otherwise
+ * false
+ */
+func (VerhoeffCheckDigit) IsValid(code string) bool {
+	if code == "" {
+		return false
+	}
+	checksum, err := verhoeffChecksum(code, true)
+	return err == nil && checksum == 0
+}
+
+// verhoeffChecksum calculates the checksum of a code, which includes
+// the check digit if includesCheckDigit is set.
+func verhoeffChecksum(code string, includesCheckDigit bool) (int, *CheckDigitError) {
+	checksum := 0
+	for i := 0; i < len(code); i++ {
+		idx := len(code) - (i + 1)
+		num, ok := toDigit(code[idx])
+		if !ok {
+			return 0, invalidCharacter(code, idx)
+		}
+		pos := i
+		if !includesCheckDigit {
+			pos++
+		}
+		checksum = verhoeffDTable[checksum][verhoeffPTable[pos%8][num]]
+	}
+	return checksum, nil
+}
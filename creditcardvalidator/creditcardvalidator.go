@@ -0,0 +1,527 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/CreditCardValidator.java?view=log
+ */
+package creditcardvalidator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dsparling/go-commons-validator/checkdigit"
+	"github.com/dsparling/go-commons-validator/regexvalidator"
+)
+
+const (
+	/**
+	 * Option specifying that no cards are allowed. This is useful if
+	 * you want only custom card types to validate so you turn off the
+	 * default cards with this option.
+	 */
+	NONE int64 = 0
+
+	/**
+	 * Option specifying that American Express cards are allowed.
+	 */
+	AMEX int64 = 1 << 0
+
+	/**
+	 * Option specifying that Visa cards are allowed.
+	 */
+	VISA int64 = 1 << 1
+
+	/**
+	 * Option specifying that Mastercard cards are allowed, including
+	 * the 2-series introduced in 2016.
+	 */
+	MASTERCARD int64 = 1 << 2
+
+	/**
+	 * Option specifying that Discover cards are allowed.
+	 */
+	DISCOVER int64 = 1 << 3
+
+	/**
+	 * Option specifying that Diners cards are allowed.
+	 */
+	DINERS int64 = 1 << 4
+
+	/**
+	 * Option specifying that JCB cards are allowed.
+	 */
+	JCB int64 = 1 << 5
+
+	/**
+	 * Option specifying that UnionPay cards are allowed.
+	 */
+	UNIONPAY int64 = 1 << 6
+
+	/**
+	 * Option specifying that Maestro cards are allowed.
+	 */
+	MAESTRO int64 = 1 << 7
+
+	/**
+	 * The cards allowed by the default validator: American Express,
+	 * Visa, Mastercard and Discover.
+	 */
+	DEFAULT int64 = AMEX | VISA | MASTERCARD | DISCOVER
+)
+
+var (
+	ErrEmpty             = errors.New("creditcardvalidator: empty card number")
+	ErrInvalidCharacter  = errors.New("creditcardvalidator: invalid character")
+	ErrUnknownIssuer     = errors.New("creditcardvalidator: unknown issuer")
+	ErrInvalidLength     = errors.New("creditcardvalidator: invalid length for issuer")
+	ErrInvalidCheckDigit = errors.New("creditcardvalidator: invalid check digit")
+)
+
+/**
+ * ValidationError reports why a card number failed validation. For
+ * ErrInvalidCharacter, Segment is the first character that isn't a
+ * digit; for ErrInvalidCheckDigit it is the last digit of the number.
+ * For ErrUnknownIssuer and ErrInvalidLength it is the whole number,
+ * without surrounding white space, as no single digit is at fault.
+ * Offset is the byte offset of Segment in the string passed to
+ * Validate. ErrEmpty has neither.
+ */
+type ValidationError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * Class that represents a credit card range: the issuer identification
+ * numbers (IIN) from Low to High and the allowed lengths of the card
+ * numbers starting with them.
+ *
+ * Low and High are prefixes of the card number and are compared with
+ * the same number of leading digits of it. If High is empty, only the
+ * prefix Low matches. The length of the card number must be between
+ * MinLen and MaxLen inclusive, or if Lengths is set, one of Lengths.
+ */
+type CreditCardRange struct {
+	Low     string // e.g. "34" or "644"
+	High    string // e.g. "34" or "649", empty for Low only
+	MinLen  int    // e.g. 15 or 16
+	MaxLen  int    // e.g. 15 or 19
+	Lengths []int  // e.g. 16, 18, 19
+}
+
+// matchesPrefix reports whether card starts with an IIN of the range.
+func (r *CreditCardRange) matchesPrefix(card string) bool {
+	if len(card) < len(r.Low) {
+		return false
+	}
+	if r.High == "" {
+		return card[:len(r.Low)] == r.Low
+	}
+	return len(card) >= len(r.High) && r.Low <= card[:len(r.Low)] && card[:len(r.High)] <= r.High
+}
+
+// validLength reports whether a card number of length valueLength is
+// allowed by the range.
+func (r *CreditCardRange) validLength(valueLength int) bool {
+	if r.Lengths != nil {
+		for _, length := range r.Lengths {
+			if valueLength == length {
+				return true
+			}
+		}
+		return false
+	}
+	return valueLength >= r.MinLen && valueLength <= r.MaxLen
+}
+
+/**
+ * Issuer describes the card numbers of one card type, like
+ * CodeValidator in Commons Validator: the IIN ranges and lengths of its
+ * numbers, or a regular expression they match, and the check digit
+ * routine they are validated with. A number belongs to the issuer if it
+ * matches one of Ranges or Regex. A nil CheckDigit skips the check
+ * digit validation.
+ */
+type Issuer struct {
+	Name       string
+	Ranges     []CreditCardRange
+	Regex      *regexvalidator.RegexValidator
+	CheckDigit checkdigit.CheckDigit
+}
+
+// match reports whether card starts with an IIN of the issuer, and
+// whether its length is one allowed for that IIN. A card matched by
+// Regex matches both.
+func (i *Issuer) match(card string) (prefix, length bool) {
+	for r := range i.Ranges {
+		if i.Ranges[r].matchesPrefix(card) {
+			prefix = true
+			if i.Ranges[r].validLength(len(card)) {
+				return true, true
+			}
+		}
+	}
+	if i.Regex != nil && i.Regex.IsValid(card) {
+		return true, true
+	}
+	return prefix, false
+}
+
+// clone returns a copy of the issuer that shares no slices with it, so
+// that changing one doesn't change the other.
+func (i Issuer) clone() Issuer {
+	if i.Ranges != nil {
+		ranges := make([]CreditCardRange, len(i.Ranges))
+		for r, cr := range i.Ranges {
+			if cr.Lengths != nil {
+				cr.Lengths = append([]int(nil), cr.Lengths...)
+			}
+			ranges[r] = cr
+		}
+		i.Ranges = ranges
+	}
+	return i
+}
+
+/**
+ * American Express (Amex) Card Issuer.
+ */
+var AMEX_ISSUER = Issuer{
+	Name: "American Express",
+	Ranges: []CreditCardRange{
+		{Low: "34", MinLen: 15, MaxLen: 15},
+		{Low: "37", MinLen: 15, MaxLen: 15},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * Visa Card Issuer.
+ */
+var VISA_ISSUER = Issuer{
+	Name: "Visa",
+	Ranges: []CreditCardRange{
+		{Low: "4", Lengths: []int{13, 16, 19}},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * Mastercard Card Issuer, with the 2221 to 2720 range added in 2016.
+ */
+var MASTERCARD_ISSUER = Issuer{
+	Name: "Mastercard",
+	Ranges: []CreditCardRange{
+		{Low: "51", High: "55", MinLen: 16, MaxLen: 16},
+		{Low: "2221", High: "2720", MinLen: 16, MaxLen: 16},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * Discover Card Issuer, including the 622126 to 622925 range
+ * co-branded with UnionPay.
+ */
+var DISCOVER_ISSUER = Issuer{
+	Name: "Discover",
+	Ranges: []CreditCardRange{
+		{Low: "6011", MinLen: 16, MaxLen: 19},
+		{Low: "622126", High: "622925", MinLen: 16, MaxLen: 19},
+		{Low: "644", High: "649", MinLen: 16, MaxLen: 19},
+		{Low: "65", MinLen: 16, MaxLen: 19},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * Diners Card Issuer.
+ */
+var DINERS_ISSUER = Issuer{
+	Name: "Diners Club",
+	Ranges: []CreditCardRange{
+		{Low: "300", High: "305", MinLen: 14, MaxLen: 19},
+		{Low: "3095", MinLen: 14, MaxLen: 19},
+		{Low: "36", MinLen: 14, MaxLen: 19},
+		{Low: "38", High: "39", MinLen: 14, MaxLen: 19},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * JCB Card Issuer.
+ */
+var JCB_ISSUER = Issuer{
+	Name: "JCB",
+	Ranges: []CreditCardRange{
+		{Low: "3528", High: "3589", MinLen: 16, MaxLen: 19},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * UnionPay Card Issuer.
+ */
+var UNIONPAY_ISSUER = Issuer{
+	Name: "UnionPay",
+	Ranges: []CreditCardRange{
+		{Low: "62", MinLen: 16, MaxLen: 19},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+/**
+ * Maestro Card Issuer.
+ */
+var MAESTRO_ISSUER = Issuer{
+	Name: "Maestro",
+	Ranges: []CreditCardRange{
+		{Low: "5018", MinLen: 12, MaxLen: 19},
+		{Low: "5020", MinLen: 12, MaxLen: 19},
+		{Low: "5038", MinLen: 12, MaxLen: 19},
+		{Low: "5893", MinLen: 12, MaxLen: 19},
+		{Low: "6304", MinLen: 12, MaxLen: 19},
+		{Low: "6759", MinLen: 12, MaxLen: 19},
+		{Low: "6761", High: "6763", MinLen: 12, MaxLen: 19},
+	},
+	CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+}
+
+// The issuers of the options, in the order they are checked
+var optionIssuers = []struct {
+	option int64
+	issuer *Issuer
+}{
+	{AMEX, &AMEX_ISSUER},
+	{VISA, &VISA_ISSUER},
+	{MASTERCARD, &MASTERCARD_ISSUER},
+	{DISCOVER, &DISCOVER_ISSUER},
+	{DINERS, &DINERS_ISSUER},
+	{JCB, &JCB_ISSUER},
+	{UNIONPAY, &UNIONPAY_ISSUER},
+	{MAESTRO, &MAESTRO_ISSUER},
+}
+
+/**
+ * Perform credit card validations.
+ *
+ * By default, American Express, Visa, Mastercard and Discover card
+ * types are allowed. You can specify which cards should pass
+ * validation by configuring the validation options. For example,
+ *
+ *	v := creditcardvalidator.New(creditcardvalidator.AMEX | creditcardvalidator.VISA)
+ *
+ * configures the validator to only pass American Express and Visa
+ * cards. If a card type is not directly supported by this class, you
+ * can create an Issuer for it and pass it to NewWithIssuers.
+ *
+ * Card numbers are checked against the IIN ranges and lengths of the
+ * issuers, and their check digit is validated with the Luhn algorithm.
+ * Numbers must consist of digits only; separators such as spaces or
+ * dashes have to be removed first.
+ */
+type CreditCardValidator struct {
+	issuers []Issuer
+}
+
+/**
+ * Creates a new CreditCardValidator with the specified options.
+ * @param options Pass in
+ * VISA + AMEX to specify that
+ * those are the only valid card types.
+ * @return the configured validator
+ */
+func New(options int64) *CreditCardValidator {
+	v := &CreditCardValidator{}
+	for _, oi := range optionIssuers {
+		if options&oi.option != 0 {
+			v.issuers = append(v.issuers, oi.issuer.clone())
+		}
+	}
+	return v
+}
+
+/**
+ * Create a new CreditCardValidator with the specified issuers, checked
+ * in order. The validator keeps copies of the issuers, so changing
+ * their Ranges afterwards doesn't affect it.
+ * @param issuers Set of valid card issuers
+ * @return the configured validator
+ */
+func NewWithIssuers(issuers ...Issuer) *CreditCardValidator {
+	v := &CreditCardValidator{issuers: make([]Issuer, len(issuers))}
+	for i, issuer := range issuers {
+		v.issuers[i] = issuer.clone()
+	}
+	return v
+}
+
+var defaultValidator = New(DEFAULT)
+
+/**
+ * Checks if the field is a valid credit card number, using the default
+ * validator.
+ * @param card The card number to validate.
+ * @return Whether the card number is valid.
+ */
+func IsValid(card string) bool {
+	return defaultValidator.IsValid(card)
+}
+
+/**
+ * Checks if the field is a valid credit card number.
+ * @param card The card number to validate.
+ * @return Whether the card number is valid.
+ */
+func (v *CreditCardValidator) IsValid(card string) bool {
+	_, err := v.validate(card)
+	return err == nil
+}
+
+/**
+ * Validates a credit card number using the default validator.
+ * @param card The card number to validate.
+ * @return nil if the card number is valid, otherwise a
+ * *ValidationError.
+ */
+func Validate(card string) error {
+	return defaultValidator.Validate(card)
+}
+
+/**
+ * Validates a credit card number, reporting why it is invalid.
+ * Leading and trailing whitespace is ignored, but offsets in the
+ * returned error are relative to card as given.
+ * @param card The card number to validate.
+ * @return nil if the card number is valid, otherwise a
+ * *ValidationError.
+ */
+func (v *CreditCardValidator) Validate(card string) error {
+	if _, err := v.validate(card); err != nil {
+		return err
+	}
+	return nil
+}
+
+/**
+ * Validates a credit card number using the default validator and
+ * returns its issuer.
+ * @param card The card number to validate.
+ * @return the issuer, and nil if the card number is valid, otherwise a
+ * *ValidationError.
+ */
+func Parse(card string) (Issuer, error) {
+	return defaultValidator.Parse(card)
+}
+
+/**
+ * Validates a credit card number and returns the issuer it is valid
+ * for, the first one if several are.
+ * @param card The card number to validate.
+ * @return the issuer, and nil if the card number is valid, otherwise a
+ * *ValidationError.
+ */
+func (v *CreditCardValidator) Parse(card string) (Issuer, error) {
+	issuer, err := v.validate(card)
+	if err != nil {
+		return Issuer{}, err
+	}
+	return issuer.clone(), nil
+}
+
+/**
+ * Detects the issuer of a card number using the default validator.
+ * @param card The card number, or its leading digits.
+ * @return the issuer, and true if one was found.
+ */
+func DetectIssuer(card string) (Issuer, bool) {
+	return defaultValidator.DetectIssuer(card)
+}
+
+/**
+ * Detects the issuer of a card number from its leading digits, as when
+ * showing the card type while the number is typed. The length and check
+ * digit are not validated, so the number may be incomplete, but like
+ * Validate it must consist of digits only. Issuers defined by a Regex
+ * are only detected when it matches the whole number.
+ * @param card The card number, or its leading digits.
+ * @return the first issuer whose IIN ranges match, and true, otherwise
+ * false.
+ */
+func (v *CreditCardValidator) DetectIssuer(card string) (Issuer, bool) {
+	card = strings.TrimSpace(card)
+	for i := 0; i < len(card); i++ {
+		if c := card[i]; c < '0' || c > '9' {
+			return Issuer{}, false
+		}
+	}
+	for i := range v.issuers {
+		if prefix, _ := v.issuers[i].match(card); prefix {
+			return v.issuers[i].clone(), true
+		}
+	}
+	return Issuer{}, false
+}
+
+// validate returns the first issuer card is valid for, or the reason it
+// isn't valid for any.
+func (v *CreditCardValidator) validate(card string) (*Issuer, *ValidationError) {
+	lead := len(card) - len(strings.TrimLeftFunc(card, unicode.IsSpace))
+	number := strings.TrimSpace(card)
+	if number == "" {
+		return nil, &ValidationError{Err: ErrEmpty}
+	}
+	for i := 0; i < len(number); i++ {
+		if c := number[i]; c < '0' || c > '9' {
+			_, size := utf8.DecodeRuneInString(number[i:])
+			return nil, &ValidationError{Err: ErrInvalidCharacter, Segment: number[i : i+size], Offset: lead + i}
+		}
+	}
+
+	prefixMatched, lengthMatched := false, false
+	for i := range v.issuers {
+		issuer := &v.issuers[i]
+		prefix, length := issuer.match(number)
+		if prefix && length {
+			if issuer.CheckDigit == nil || issuer.CheckDigit.IsValid(number) {
+				return issuer, nil
+			}
+			lengthMatched = true
+		} else if prefix {
+			prefixMatched = true
+		}
+	}
+
+	switch {
+	case lengthMatched:
+		last := len(number) - 1
+		return nil, &ValidationError{Err: ErrInvalidCheckDigit, Segment: number[last:], Offset: lead + last}
+	case prefixMatched:
+		return nil, &ValidationError{Err: ErrInvalidLength, Segment: number, Offset: lead}
+	}
+	return nil, &ValidationError{Err: ErrUnknownIssuer, Segment: number, Offset: lead}
+}
@@ -0,0 +1,347 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/CreditCardValidatorTest.java?view=log
+ */
+package creditcardvalidator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dsparling/go-commons-validator/checkdigit"
+	"github.com/dsparling/go-commons-validator/regexvalidator"
+)
+
+const (
+	VALID_VISA       = "4417123456789113" // 16
+	VALID_SHORT_VISA = "4222222222222"    // 13
+	VALID_LONG_VISA  = "4123456789012345677"
+	VALID_AMEX       = "378282246310005"  // 15
+	VALID_MASTERCARD = "5105105105105100" // 16
+	VALID_MC_2SERIES = "2221000000000009"
+	VALID_DISCOVER   = "6011000990139424"
+	VALID_DISCOVER65 = "6500000000000000003"
+	VALID_DINERS     = "30569309025904" // 14
+	VALID_DINERS36   = "3600000000000000004"
+	VALID_JCB        = "3530111333300000"
+	VALID_UNIONPAY   = "6200000000000005"
+	VALID_MAESTRO    = "6759649826438453"
+	ERROR_VISA       = "4417123456789112"
+	ERROR_AMEX       = "378282246310001"
+)
+
+const ALL = AMEX | VISA | MASTERCARD | DISCOVER | DINERS | JCB | UNIONPAY | MAESTRO
+
+func TestIsValid(t *testing.T) {
+	validCards := []string{
+		VALID_VISA,
+		VALID_SHORT_VISA,
+		VALID_LONG_VISA,
+		VALID_AMEX,
+		VALID_MASTERCARD,
+		VALID_MC_2SERIES,
+		VALID_DISCOVER,
+		VALID_DISCOVER65,
+		" " + VALID_VISA + "\n",
+	}
+	for _, card := range validCards {
+		if !IsValid(card) {
+			t.Errorf("expected valid card: %s", card)
+		}
+	}
+
+	invalidCards := []string{
+		"",
+		"123456789012",         // too short
+		"12345678901234567890", // too long
+		ERROR_VISA,
+		ERROR_AMEX,
+		"4417q23456789113",
+		"4417 1234 5678 9113",
+		"4417-1234-5678-9113",
+		VALID_DINERS, // not a default card type
+		VALID_JCB,
+		VALID_UNIONPAY,
+		VALID_MAESTRO,
+	}
+	for _, card := range invalidCards {
+		if IsValid(card) {
+			t.Errorf("expected invalid card: %s", card)
+		}
+	}
+}
+
+/**
+ * Test a validator only passes the card types of its options.
+ */
+func TestOptions(t *testing.T) {
+	cards := []struct {
+		option int64
+		cards  []string
+	}{
+		{AMEX, []string{VALID_AMEX, "371449635398431"}},
+		{VISA, []string{VALID_VISA, VALID_SHORT_VISA, VALID_LONG_VISA, "4111111111111111"}},
+		{MASTERCARD, []string{VALID_MASTERCARD, VALID_MC_2SERIES, "2720990000000007", "2223003122003222", "5555555555554444"}},
+		{DISCOVER, []string{VALID_DISCOVER, VALID_DISCOVER65, "6445644564456445", "6011000000000000001"}},
+		{DINERS, []string{VALID_DINERS, VALID_DINERS36, "38520000023237", "36227206271667", "30950000000000"}},
+		{JCB, []string{VALID_JCB, "3566002020360505", "3589000000000000009"}},
+		{UNIONPAY, []string{VALID_UNIONPAY, "6205500000000000004", "6229260000000002"}},
+		{MAESTRO, []string{VALID_MAESTRO, "676300000004", "5018000000000009", "5018000000000000007"}},
+	}
+	for _, tt := range cards {
+		v := New(tt.option)
+		for _, other := range cards {
+			for _, card := range other.cards {
+				if got, want := v.IsValid(card), other.option == tt.option; got != want {
+					t.Errorf("option %d: IsValid(%s) = %v, want %v", tt.option, card, got, want)
+				}
+			}
+		}
+
+		all := New(ALL)
+		for _, card := range tt.cards {
+			if !all.IsValid(card) {
+				t.Errorf("expected valid card: %s", card)
+			}
+		}
+	}
+
+	// Co-branded cards belong to both
+	for _, option := range []int64{DISCOVER, UNIONPAY} {
+		if !New(option).IsValid("62212600000000001") {
+			t.Errorf("option %d: expected co-branded card to be valid", option)
+		}
+	}
+
+	none := New(NONE)
+	if none.IsValid(VALID_VISA) {
+		t.Errorf("expected no card to be valid with NONE")
+	}
+}
+
+/**
+ * Test card numbers of the right issuer but the wrong length.
+ */
+func TestLengths(t *testing.T) {
+	v := New(ALL)
+	invalidCards := []string{
+		"42222222222220",       // Visa, 14
+		"422222222222223",      // Visa, 15
+		"3782822463100005",     // Amex, 16
+		"510510510510510",      // Mastercard, 15
+		"60110009901394",       // Discover, 14
+		"3056930902590",        // Diners, 13
+		"35890000000000000003", // JCB, 20
+		"67630000004",          // Maestro, 11
+	}
+	for _, card := range invalidCards {
+		if err := v.Validate(card); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("Validate(%s): expected ErrInvalidLength, got %v", card, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		card    string
+		err     error
+		segment string
+		offset  int
+	}{
+		{VALID_VISA, nil, "", 0},
+		{"", ErrEmpty, "", 0},
+		{"  ", ErrEmpty, "", 0},
+		{"4417q23456789113", ErrInvalidCharacter, "q", 4},
+		{" 4417 1234", ErrInvalidCharacter, " ", 5},
+		{"4417€", ErrInvalidCharacter, "€", 4},
+		{ERROR_VISA, ErrInvalidCheckDigit, "2", 15},
+		{" " + ERROR_AMEX, ErrInvalidCheckDigit, "1", 15},
+		{"4222222222222222222222", ErrInvalidLength, "4222222222222222222222", 0},
+		{"2721000000000000", ErrUnknownIssuer, "2721000000000000", 0},
+		{"\t" + VALID_JCB, ErrUnknownIssuer, VALID_JCB, 1},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.card)
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q): unexpected error %v", tt.card, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Validate(%q): expected *ValidationError, got %v", tt.card, err)
+			continue
+		}
+		if verr.Err != tt.err || verr.Segment != tt.segment || verr.Offset != tt.offset {
+			t.Errorf("Validate(%q): got %v, %q, %d, want %v, %q, %d",
+				tt.card, verr.Err, verr.Segment, verr.Offset, tt.err, tt.segment, tt.offset)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	v := New(ALL)
+	tests := []struct {
+		card   string
+		issuer string
+	}{
+		{VALID_VISA, "Visa"},
+		{VALID_AMEX, "American Express"},
+		{VALID_MC_2SERIES, "Mastercard"},
+		{VALID_DISCOVER65, "Discover"},
+		{"62212600000000001", "Discover"}, // co-branded with UnionPay
+		{VALID_UNIONPAY, "UnionPay"},
+		{VALID_DINERS, "Diners Club"},
+		{VALID_JCB, "JCB"},
+		{VALID_MAESTRO, "Maestro"},
+	}
+	for _, tt := range tests {
+		issuer, err := v.Parse(tt.card)
+		if err != nil || issuer.Name != tt.issuer {
+			t.Errorf("Parse(%s): got %q, %v, want %q", tt.card, issuer.Name, err, tt.issuer)
+		}
+	}
+
+	if issuer, err := Parse(ERROR_VISA); err == nil || issuer.Name != "" {
+		t.Errorf("Parse(%s): expected error, got %q", ERROR_VISA, issuer.Name)
+	}
+}
+
+func TestDetectIssuer(t *testing.T) {
+	v := New(ALL)
+	tests := []struct {
+		card   string
+		issuer string
+	}{
+		{"4", "Visa"},
+		{" 441712 ", "Visa"},
+		{"37", "American Express"},
+		{"2221", "Mastercard"},
+		{"2720", "Mastercard"},
+		{"55", "Mastercard"},
+		{"644", "Discover"},
+		{"3095", "Diners Club"},
+		{"3528", "JCB"},
+		{"62", "UnionPay"},
+		{"6763", "Maestro"},
+		{ERROR_VISA, "Visa"}, // the check digit isn't validated
+	}
+	for _, tt := range tests {
+		issuer, ok := v.DetectIssuer(tt.card)
+		if !ok || issuer.Name != tt.issuer {
+			t.Errorf("DetectIssuer(%s): got %q, %v, want %q", tt.card, issuer.Name, ok, tt.issuer)
+		}
+	}
+
+	unknownCards := []string{"", "1", "2", "2721", "35", "56", "9", "4abc", "4417 12", "4-"}
+	for _, card := range unknownCards {
+		if issuer, ok := v.DetectIssuer(card); ok {
+			t.Errorf("DetectIssuer(%s): got %q", card, issuer.Name)
+		}
+	}
+
+	// Only the default card types
+	if issuer, ok := DetectIssuer(VALID_JCB); ok {
+		t.Errorf("DetectIssuer(%s): got %q", VALID_JCB, issuer.Name)
+	}
+}
+
+/**
+ * Test custom card types, with and without the default ones.
+ */
+func TestCustomIssuer(t *testing.T) {
+	store := Issuer{
+		Name:       "Store Card",
+		Regex:      regexvalidator.MustNew([]string{`^(99)(\d{10})$`}),
+		CheckDigit: checkdigit.LUHN_CHECK_DIGIT,
+	}
+	gift := Issuer{
+		Name: "Gift Card",
+		Ranges: []CreditCardRange{
+			{Low: "1000", High: "1999", MinLen: 8, MaxLen: 8},
+		},
+	}
+
+	v := NewWithIssuers(store, gift)
+	validCards := []string{"990012345676", "10000000", "19991234"}
+	for _, card := range validCards {
+		if !v.IsValid(card) {
+			t.Errorf("expected valid card: %s", card)
+		}
+	}
+	invalidCards := []string{"990012345675", "99001234567", "20000000", "100000000", VALID_VISA}
+	for _, card := range invalidCards {
+		if v.IsValid(card) {
+			t.Errorf("expected invalid card: %s", card)
+		}
+	}
+
+	if issuer, err := v.Parse("990012345676"); err != nil || issuer.Name != "Store Card" {
+		t.Errorf("Parse: got %q, %v", issuer.Name, err)
+	}
+	if err := v.Validate("990012345675"); !errors.Is(err, ErrInvalidCheckDigit) {
+		t.Errorf("expected ErrInvalidCheckDigit, got %v", err)
+	}
+
+	// Added to the default card types
+	withDefaults := NewWithIssuers(AMEX_ISSUER, VISA_ISSUER, MASTERCARD_ISSUER, DISCOVER_ISSUER, store)
+	for _, card := range []string{VALID_VISA, VALID_AMEX, "990012345676"} {
+		if !withDefaults.IsValid(card) {
+			t.Errorf("expected valid card: %s", card)
+		}
+	}
+}
+
+/**
+ * Test that validators don't share the Ranges of the issuers they are
+ * created with or return.
+ */
+func TestIssuerCopies(t *testing.T) {
+	gift := Issuer{
+		Name: "Gift Card",
+		Ranges: []CreditCardRange{
+			{Low: "1000", High: "1999", Lengths: []int{8}},
+		},
+	}
+	custom := NewWithIssuers(gift)
+	visa := New(VISA)
+
+	gift.Ranges[0].Low = "2000"
+	gift.Ranges[0].Lengths[0] = 9
+	VISA_ISSUER.Ranges[0].Low = "5"
+	defer func() { VISA_ISSUER.Ranges[0].Low = "4" }()
+
+	if !custom.IsValid("10000000") {
+		t.Errorf("NewWithIssuers shares the Ranges of its issuers")
+	}
+	if !visa.IsValid(VALID_VISA) {
+		t.Errorf("New shares the Ranges of VISA_ISSUER")
+	}
+
+	issuer, err := custom.Parse("10000000")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	issuer.Ranges[0].Lengths[0] = 9
+	if issuer, ok := custom.DetectIssuer("1"); ok {
+		issuer.Ranges[0].Low = "2000"
+	}
+	if !custom.IsValid("10000000") {
+		t.Errorf("Parse or DetectIssuer returned the Ranges of the validator")
+	}
+}
@@ -18,72 +18,506 @@
  */
 package domainvalidator
 
+//go:generate go run ./internal/tldgen -tlds tlds-alpha-by-domain.txt -rootdb root-zone-db.html -out tlds.go
+
 import (
-	//"fmt"
-	"regexp"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/dsparling/go-commons-validator/regexvalidator"
+	"golang.org/x/net/idna"
 )
 
 const (
-	DOMAIN_LABEL_REGEX = "-*[a-zA-Z0-9-]{1,63}[-]*" // TODO - Fix work around with leading/trailing hypens
-	TOP_LABEL_REGEX    = "[A-Za-z]{2,}"
+	DOMAIN_LABEL_REGEX = "[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?"
+	TOP_LABEL_REGEX    = "[A-Za-z](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?"
 	DOMAIN_NAME_REGEX  = "^(?:" + "(" + DOMAIN_LABEL_REGEX + ")" + "\\.)+" + "(" + TOP_LABEL_REGEX + ")$"
+	HOSTNAME_REGEX     = "^" + DOMAIN_LABEL_REGEX + "$"
 )
 
-var INFRASTRUCTURE_TLDS []string
-var GENERIC_TLDS []string
-var COUNTRY_CODE_TLDS []string
+const (
+	MAX_LABEL_LENGTH = 63
+	// 255 octets in the wire format, less the length octet of the first
+	// label and the root label
+	MAX_DOMAIN_LENGTH = 253
+)
+
+// ACE_PREFIX starts every A-label, the ASCII form of an internationalized label
+const ACE_PREFIX = "xn--"
+
+// Compiled once; a RegexValidator is safe for concurrent use
+var (
+	domainRegex   = regexvalidator.MustNew([]string{DOMAIN_NAME_REGEX})
+	hostnameRegex = regexvalidator.MustNew([]string{HOSTNAME_REGEX})
+)
+
+// idnaProfile maps and checks internationalized names as for a UTS #46
+// lookup with IDNA2008 (non-transitional) processing. Hyphen placement
+// is left to the label rules of this package.
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.Transitional(false),
+	idna.CheckHyphens(false),
+)
+
+var (
+	ErrEmpty         = errors.New("domainvalidator: empty domain name")
+	ErrInvalidDomain = errors.New("domainvalidator: invalid domain name")
+	ErrUnknownTLD    = errors.New("domainvalidator: unknown top-level domain")
+	ErrLabelTooLong  = errors.New("domainvalidator: label longer than 63 octets")
+	ErrDomainTooLong = errors.New("domainvalidator: domain name too long")
+)
+
+/**
+ * ValidationError reports why a domain name failed validation.
+ * Err is one of the Err* values of this package, Segment is the
+ * offending part of the input and Offset is its byte offset.
+ */
+type ValidationError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// The TLD tables. They are read once, when the package is initialized,
+// into the sets used for lookups; changing them afterwards has no effect
+// and races with validation. Use DomainValidator.UpdateTLDOverride to
+// add or remove TLDs instead.
+// INFRASTRUCTURE_TLDS, GENERIC_TLDS and COUNTRY_CODE_TLDS are generated
+// into tlds.go from the committed tlds-alpha-by-domain.txt and
+// root-zone-db.html, see internal/tldgen. The Source line of tlds.go
+// names the version of the list they came from.
 var LOCAL_TLDS []string
 
-// TODO
-// private final boolean allowLocal;
+var (
+	infrastructureTlds tldSet
+	genericTlds        tldSet
+	countryCodeTlds    tldSet
+	localTlds          tldSet
+)
+
+/**
+ * DomainValidator validates domain names. The zero value, like the
+ * validator returned by New with no options, does not allow local
+ * domain names.
+ */
+type DomainValidator struct {
+	allowLocal bool
+
+	// TLD overrides. Updates replace the snapshot under mu; lookups
+	// load it without locking.
+	mu        sync.Mutex
+	overrides atomic.Pointer[tldOverrides]
+}
+
+// tldOverrides holds the TLD override tables, indexed by ArrayType. A
+// published snapshot is never modified.
+type tldOverrides [COUNTRY_CODE_MINUS + 1]tldSet
+
+/**
+ * ArrayType selects a TLD override table of a DomainValidator.
+ */
+type ArrayType int
+
+const (
+	// Update (or get a copy of) the GENERIC_PLUS table containing additional generic TLDs
+	GENERIC_PLUS ArrayType = iota
+	// Update (or get a copy of) the GENERIC_MINUS table containing deleted generic TLDs
+	GENERIC_MINUS
+	// Update (or get a copy of) the COUNTRY_CODE_PLUS table containing additional country code TLDs
+	COUNTRY_CODE_PLUS
+	// Update (or get a copy of) the COUNTRY_CODE_MINUS table containing deleted country code TLDs
+	COUNTRY_CODE_MINUS
+)
+
+/**
+ * Domain holds both forms of a valid domain name: ASCII, in which
+ * internationalized labels are A-labels (xn--...), and Unicode, in
+ * which they are U-labels.
+ */
+type Domain struct {
+	ASCII   string
+	Unicode string
+}
+
+/**
+ * Option configures a DomainValidator created with New.
+ */
+type Option func(*DomainValidator)
+
+/**
+ * AllowLocal sets whether local addresses such as localhost,
+ * localhost.localdomain and single-label hostnames are considered valid.
+ * @param allowLocal Should local addresses be considered valid?
+ */
+func AllowLocal(allowLocal bool) Option {
+	return func(v *DomainValidator) {
+		v.allowLocal = allowLocal
+	}
+}
+
+/**
+ * New returns a DomainValidator configured with the given options.
+ * @param opts the options to apply
+ * @return the configured validator
+ */
+func New(opts ...Option) *DomainValidator {
+	v := &DomainValidator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+var defaultValidator = New()
+
+/**
+ * Does this validator allow local addresses?
+ * @return true if local addresses are allowed
+ */
+func (v *DomainValidator) IsAllowLocal() bool {
+	return v.allowLocal
+}
 
 /**
  * Returns true if the specified <code>String</code> parses
- * as a valid domain name with a recognized top-level domain.
+ * as a valid domain name with a recognized top-level domain,
+ * using the default validator which does not allow local names.
+ * Internationalized names may be given in Unicode or as A-labels.
  * The parsing is case-sensitive.
  * @param domain the parameter to check for domain name syntax
  * @return true if the parameter is a valid domain name
  */
 func IsValid(domain string) bool {
-	//fmt.Println("IsValid:(domain) ", domain)
-	domainRegex, _ := regexp.Compile(DOMAIN_NAME_REGEX)
-	match := domainRegex.MatchString(domain)
-	if match {
-		groups := domainRegex.FindStringSubmatch(domain)
-		if groups != nil && len(groups) > 0 {
-			dnsLabel := groups[1]
-			// Check prefix/suffix for now to get around invalid or unsupported Perl syntax in REGEX
-			if strings.HasPrefix(dnsLabel, "-") {
-				return false
-			} else if strings.HasSuffix(dnsLabel, "-") {
-				return false
-			}
-			tld := groups[2]
-			return IsValidTld(tld)
-			// TODO
-			//} else if(allowLocal) {
-			//    if (hostnameRegex.isValid(domain)) {
-			//       return true;
-			//    }
+	return defaultValidator.IsValid(domain)
+}
+
+/**
+ * Returns true if the specified <code>String</code> parses
+ * as a valid domain name with a recognized top-level domain.
+ * Unicode input is converted to A-labels first, and A-labels
+ * must decode to valid internationalized labels.
+ * The parsing is case-sensitive.
+ * @param domain the parameter to check for domain name syntax
+ * @return true if the parameter is a valid domain name
+ */
+func (v *DomainValidator) IsValid(domain string) bool {
+	domain, ok := toASCII(domain)
+	if !ok {
+		return false
+	}
+	if len(domain) > MAX_DOMAIN_LENGTH {
+		return false
+	}
+	if domainRegex.IsValid(domain) {
+		if hasReservedLabel(domain) {
+			return false
 		}
+		return v.IsValidTld(domain[strings.LastIndexByte(domain, '.')+1:])
+	} else if v.allowLocal {
+		return hostnameRegex.IsValid(domain) && !hasReservedLabel(domain)
 	}
 	return false
 }
 
+/**
+ * Validates the specified <code>String</code> as a domain name with a
+ * recognized top-level domain, using the default validator.
+ * @param domain the parameter to check for domain name syntax
+ * @return nil if the parameter is a valid domain name, otherwise
+ * a *ValidationError
+ */
+func Validate(domain string) error {
+	return defaultValidator.Validate(domain)
+}
+
+/**
+ * Validates the specified <code>String</code> as a domain name with a
+ * recognized top-level domain. It accepts exactly what IsValid accepts.
+ * @param domain the parameter to check for domain name syntax
+ * @return nil if the parameter is a valid domain name, otherwise
+ * a *ValidationError
+ */
+func (v *DomainValidator) Validate(domain string) error {
+	if domain == "" {
+		return &ValidationError{Err: ErrEmpty}
+	}
+	if v.IsValid(domain) {
+		return nil
+	}
+
+	if ascii, ok := toASCII(domain); ok && len(ascii) > MAX_DOMAIN_LENGTH {
+		return &ValidationError{Err: ErrDomainTooLong, Segment: domain}
+	}
+
+	// Find the first label at fault. Labels are checked in their
+	// ASCII form but reported as given.
+	offset := 0
+	for {
+		end, size := labelEnd(domain[offset:])
+		label := domain[offset : offset+end]
+		ascii, ok := toASCII(label)
+		if !ok {
+			return &ValidationError{Err: ErrInvalidDomain, Segment: label, Offset: offset}
+		}
+		if len(ascii) > MAX_LABEL_LENGTH {
+			return &ValidationError{Err: ErrLabelTooLong, Segment: label, Offset: offset}
+		}
+		if size == 0 && offset > 0 && isLabel(ascii) {
+			return &ValidationError{Err: ErrUnknownTLD, Segment: label, Offset: offset}
+		}
+		if !isLabel(ascii) {
+			return &ValidationError{Err: ErrInvalidDomain, Segment: label, Offset: offset}
+		}
+		if size == 0 {
+			break
+		}
+		offset += end + size
+	}
+	return &ValidationError{Err: ErrInvalidDomain, Segment: domain}
+}
+
+/**
+ * Validates the specified domain name using the default validator and
+ * returns its ASCII and Unicode forms.
+ * @param domain the parameter to check for domain name syntax
+ * @return the two forms of the domain name, and nil if it is valid,
+ * otherwise a *ValidationError
+ */
+func Parse(domain string) (Domain, error) {
+	return defaultValidator.Parse(domain)
+}
+
+/**
+ * Validates the specified domain name and returns its ASCII and
+ * Unicode forms, e.g. xn--mnchen-3ya.de and münchen.de. Both are
+ * mapped as for an IDNA lookup: they are lower case and ideographic
+ * full stops become dots.
+ * @param domain the parameter to check for domain name syntax
+ * @return the two forms of the domain name, and nil if it is valid,
+ * otherwise a *ValidationError
+ */
+func (v *DomainValidator) Parse(domain string) (Domain, error) {
+	if err := v.Validate(domain); err != nil {
+		return Domain{}, err
+	}
+	ascii, err := idnaProfile.ToASCII(domain)
+	if err != nil {
+		return Domain{}, &ValidationError{Err: ErrInvalidDomain, Segment: domain}
+	}
+	unicode, err := idnaProfile.ToUnicode(ascii)
+	if err != nil {
+		return Domain{}, &ValidationError{Err: ErrInvalidDomain, Segment: domain}
+	}
+	return Domain{ASCII: ascii, Unicode: unicode}, nil
+}
+
+/**
+ * Converts potentially Unicode input to punycode.
+ * If conversion fails, returns the original input.
+ * @param input the string to convert, not null
+ * @return converted input, or original input if conversion fails
+ */
+func UnicodeToASCII(input string) string {
+	if isASCII(input) {
+		return input
+	}
+	ascii, err := idnaProfile.ToASCII(input)
+	if err != nil {
+		return input
+	}
+	return ascii
+}
+
+// toASCII converts domain to its ASCII form, checking any A-labels.
+// ASCII names without A-labels are returned as is, without allocating.
+func toASCII(domain string) (string, bool) {
+	if isASCII(domain) && !hasALabel(domain) {
+		return domain, true
+	}
+	ascii, err := idnaProfile.ToASCII(domain)
+	return ascii, err == nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// hasALabel reports whether any label of domain starts with ACE_PREFIX.
+func hasALabel(domain string) bool {
+	for i := 0; i+len(ACE_PREFIX) <= len(domain); i++ {
+		if (i == 0 || domain[i-1] == '.') && strings.EqualFold(domain[i:i+len(ACE_PREFIX)], ACE_PREFIX) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelEnd returns the index and width of the first label separator in
+// s, or len(s) and 0 if there is none. Besides the dot, UTS #46 maps the
+// ideographic, full-width and half-width full stops to label separators.
+func labelEnd(s string) (end, size int) {
+	for i, c := range s {
+		switch c {
+		case '.', '\u3002', '\uff0e', '\uff61':
+			return i, utf8.RuneLen(c)
+		}
+	}
+	return len(s), 0
+}
+
+// isLabel reports whether label is a non-empty run of letters, digits
+// and hyphens that neither starts nor ends with a hyphen and isn't
+// reserved.
+func isLabel(label string) bool {
+	if label == "" || label[0] == '-' || label[len(label)-1] == '-' || isReserved(label) {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isReserved reports whether label has "--" in the third and fourth
+// positions without being an A-label. RFC 5891 section 4.2.3.1 reserves
+// these labels for future encodings.
+func isReserved(label string) bool {
+	return len(label) >= 4 && label[2] == '-' && label[3] == '-' &&
+		!strings.EqualFold(label[:len(ACE_PREFIX)], ACE_PREFIX)
+}
+
+// hasReservedLabel reports whether any label of domain is reserved.
+func hasReservedLabel(domain string) bool {
+	for start := 0; start < len(domain); {
+		end := strings.IndexByte(domain[start:], '.')
+		if end < 0 {
+			end = len(domain) - start
+		}
+		if isReserved(domain[start : start+end]) {
+			return true
+		}
+		start += end + 1
+	}
+	return false
+}
+
+/**
+ * Returns true if the specified <code>String</code> matches any
+ * IANA-defined top-level domain, using the default validator.
+ * Leading dots are ignored if present. The search is case-sensitive.
+ * @param tld the parameter to check for TLD status
+ * @return true if the parameter is a TLD
+ */
+func IsValidTld(tld string) bool {
+	return defaultValidator.IsValidTld(tld)
+}
+
 /**
  * Returns true if the specified <code>String</code> matches any
  * IANA-defined top-level domain. Leading dots are ignored if present.
+ * If local names are allowed, the local TLDs are also accepted.
+ * Internationalized TLDs may be given in Unicode or as A-labels.
  * The search is case-sensitive.
  * @param tld the parameter to check for TLD status
  * @return true if the parameter is a TLD
  */
-func IsValidTld(tld string) bool {
-	//fmt.Println("IsValidTld(tld) ", tld)
-	//if(allowLocal && isValidLocalTld(tld)) {
-	//   return true;
-	//}
-	return IsValidInfrastructureTld(tld) || IsValidGenericTld(tld) || IsValidCountryCodeTld(tld)
+func (v *DomainValidator) IsValidTld(tld string) bool {
+	if v.allowLocal && IsValidLocalTld(tld) {
+		return true
+	}
+	o := v.overrides.Load()
+	return IsValidInfrastructureTld(tld) || o.isValidGenericTld(tld) || o.isValidCountryCodeTld(tld)
+}
+
+/**
+ * Update one of the TLD override tables of this validator. The entries
+ * replace the previous contents of the table; they are lower cased,
+ * internationalized TLDs are converted to A-labels and a leading dot
+ * is ignored. Tables of other validators, including the
+ * one used by the package level functions, are not affected.
+ * It is safe to call while other goroutines use the validator.
+ * @param table the table to update, see ArrayType
+ * @param tlds the array of TLDs, must not be null
+ * @return an error if table is not one of the ArrayType constants
+ */
+func (v *DomainValidator) UpdateTLDOverride(table ArrayType, tlds []string) error {
+	if table < GENERIC_PLUS || table > COUNTRY_CODE_MINUS {
+		return fmt.Errorf("domainvalidator: unknown TLD override table %d", table)
+	}
+	set := make(tldSet, len(tlds))
+	for _, tld := range tlds {
+		set[tldKey(tld)] = struct{}{}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var next tldOverrides
+	if o := v.overrides.Load(); o != nil {
+		next = *o
+	}
+	next[table] = set
+	v.overrides.Store(&next)
+	return nil
+}
+
+/**
+ * Get a sorted copy of one of the TLD override tables of this validator.
+ * @param table the table to return, see ArrayType
+ * @return a copy of the table, or nil if table is not one of the
+ * ArrayType constants
+ */
+func (v *DomainValidator) GetTLDEntries(table ArrayType) []string {
+	if table < GENERIC_PLUS || table > COUNTRY_CODE_MINUS {
+		return nil
+	}
+	var set tldSet
+	if o := v.overrides.Load(); o != nil {
+		set = o[table]
+	}
+	tlds := make([]string, 0, len(set))
+	for tld := range set {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+	return tlds
+}
+
+// contains reports whether tld is in the given override table. A nil
+// snapshot has empty tables.
+func (o *tldOverrides) contains(table ArrayType, tld string) bool {
+	return o != nil && o[table].contains(tld)
+}
+
+func (o *tldOverrides) isValidGenericTld(gTld string) bool {
+	return (genericTlds.contains(gTld) || o.contains(GENERIC_PLUS, gTld)) &&
+		!o.contains(GENERIC_MINUS, gTld)
+}
+
+func (o *tldOverrides) isValidCountryCodeTld(ccTld string) bool {
+	return (countryCodeTlds.contains(ccTld) || o.contains(COUNTRY_CODE_PLUS, ccTld)) &&
+		!o.contains(COUNTRY_CODE_MINUS, ccTld)
 }
 
 /**
@@ -94,7 +528,7 @@ func IsValidTld(tld string) bool {
  * @return true if the parameter is an infrastructure TLD
  */
 func IsValidInfrastructureTld(iTld string) bool {
-	return contains(INFRASTRUCTURE_TLDS, strings.TrimPrefix(strings.ToLower(iTld), "."))
+	return infrastructureTlds.contains(iTld)
 }
 
 /**
@@ -105,7 +539,19 @@ func IsValidInfrastructureTld(iTld string) bool {
  * @return true if the parameter is a generic TLD
  */
 func IsValidGenericTld(gTld string) bool {
-	return contains(GENERIC_TLDS, strings.TrimPrefix(strings.ToLower(gTld), "."))
+	return defaultValidator.IsValidGenericTld(gTld)
+}
+
+/**
+ * Returns true if the specified <code>String</code> matches any
+ * IANA-defined generic top-level domain, or the GENERIC_PLUS table,
+ * and not the GENERIC_MINUS table. Leading dots are ignored
+ * if present. The search is case-sensitive.
+ * @param gTld the parameter to check for generic TLD status
+ * @return true if the parameter is a generic TLD
+ */
+func (v *DomainValidator) IsValidGenericTld(gTld string) bool {
+	return v.overrides.Load().isValidGenericTld(gTld)
 }
 
 /**
@@ -116,7 +562,19 @@ func IsValidGenericTld(gTld string) bool {
  * @return true if the parameter is a country code TLD
  */
 func IsValidCountryCodeTld(ccTld string) bool {
-	return contains(COUNTRY_CODE_TLDS, strings.TrimPrefix(strings.ToLower(ccTld), "."))
+	return defaultValidator.IsValidCountryCodeTld(ccTld)
+}
+
+/**
+ * Returns true if the specified <code>String</code> matches any
+ * IANA-defined country code top-level domain, or the COUNTRY_CODE_PLUS
+ * table, and not the COUNTRY_CODE_MINUS table. Leading dots are
+ * ignored if present. The search is case-sensitive.
+ * @param ccTld the parameter to check for country code TLD status
+ * @return true if the parameter is a country code TLD
+ */
+func (v *DomainValidator) IsValidCountryCodeTld(ccTld string) bool {
+	return v.overrides.Load().isValidCountryCodeTld(ccTld)
 }
 
 /**
@@ -127,304 +585,46 @@ func IsValidCountryCodeTld(ccTld string) bool {
  * @return true if the parameter is an local TLD
  */
 func IsValidLocalTld(lTld string) bool {
-	return contains(LOCAL_TLDS, strings.TrimPrefix(strings.ToLower(lTld), "."))
+	return localTlds.contains(lTld)
 }
 
-func contains(s []string, e string) bool {
-	for _, a := range s {
-		if a == e {
-			return true
-		}
-	}
-	return false
-}
+// tldSet is a set of lower case TLDs without the leading dot.
+type tldSet map[string]struct{}
 
-func init() {
-	// ----- TLDs defined by IANA
-	// ----- Authoritative and comprehensive list at:
-	// ----- http://data.iana.org/TLD/tlds-alpha-by-domain.txt
-	INFRASTRUCTURE_TLDS = []string{
-		"arpa", // internet infrastructure
-		"root", // diagnostic marker for non-truncated root zone
+func newTldSet(tlds []string) tldSet {
+	set := make(tldSet, len(tlds))
+	for _, tld := range tlds {
+		set[strings.ToLower(tld)] = struct{}{}
 	}
+	return set
+}
 
-	GENERIC_TLDS = []string{
-		"aero",   // air transport industry
-		"asia",   // Pan-Asia/Asia Pacific
-		"biz",    // businesses
-		"cat",    // Catalan linguistic/cultural community
-		"com",    // commercial enterprises
-		"coop",   // cooperative associations
-		"info",   // informational sites
-		"jobs",   // Human Resource managers
-		"mobi",   // mobile products and services
-		"museum", // museums, surprisingly enough
-		"name",   // individuals' sites
-		"net",    // internet support infrastructure/business
-		"org",    // noncommercial organizations
-		"pro",    // credentialed professionals and entities
-		"tel",    // contact data for businesses and individuals
-		"travel", // entities in the travel industry
-		"gov",    // United States Government
-		"edu",    // accredited postsecondary US education entities
-		"mil",    // United States Military
-		"int",    // organizations established by international treaty
-	}
+// contains reports whether tld, ignoring case and a leading dot, is in the set.
+func (set tldSet) contains(tld string) bool {
+	_, ok := set[tldKey(tld)]
+	return ok
+}
 
-	COUNTRY_CODE_TLDS = []string{
-		"ac", // Ascension Island
-		"ad", // Andorra
-		"ae", // United Arab Emirates
-		"af", // Afghanistan
-		"ag", // Antigua and Barbuda
-		"ai", // Anguilla
-		"al", // Albania
-		"am", // Armenia
-		"an", // Netherlands Antilles
-		"ao", // Angola
-		"aq", // Antarctica
-		"ar", // Argentina
-		"as", // American Samoa
-		"at", // Austria
-		"au", // Australia (includes Ashmore and Cartier Islands and Coral Sea Islands)
-		"aw", // Aruba
-		"ax", // Ã…land
-		"az", // Azerbaijan
-		"ba", // Bosnia and Herzegovina
-		"bb", // Barbados
-		"bd", // Bangladesh
-		"be", // Belgium
-		"bf", // Burkina Faso
-		"bg", // Bulgaria
-		"bh", // Bahrain
-		"bi", // Burundi
-		"bj", // Benin
-		"bm", // Bermuda
-		"bn", // Brunei Darussalam
-		"bo", // Bolivia
-		"br", // Brazil
-		"bs", // Bahamas
-		"bt", // Bhutan
-		"bv", // Bouvet Island
-		"bw", // Botswana
-		"by", // Belarus
-		"bz", // Belize
-		"ca", // Canada
-		"cc", // Cocos (Keeling) Islands
-		"cd", // Democratic Republic of the Congo (formerly Zaire)
-		"cf", // Central African Republic
-		"cg", // Republic of the Congo
-		"ch", // Switzerland
-		"ci", // CÃ´te d'Ivoire
-		"ck", // Cook Islands
-		"cl", // Chile
-		"cm", // Cameroon
-		"cn", // China, mainland
-		"co", // Colombia
-		"cr", // Costa Rica
-		"cu", // Cuba
-		"cv", // Cape Verde
-		"cx", // Christmas Island
-		"cy", // Cyprus
-		"cz", // Czech Republic
-		"de", // Germany
-		"dj", // Djibouti
-		"dk", // Denmark
-		"dm", // Dominica
-		"do", // Dominican Republic
-		"dz", // Algeria
-		"ec", // Ecuador
-		"ee", // Estonia
-		"eg", // Egypt
-		"er", // Eritrea
-		"es", // Spain
-		"et", // Ethiopia
-		"eu", // European Union
-		"fi", // Finland
-		"fj", // Fiji
-		"fk", // Falkland Islands
-		"fm", // Federated States of Micronesia
-		"fo", // Faroe Islands
-		"fr", // France
-		"ga", // Gabon
-		"gb", // Great Britain (United Kingdom)
-		"gd", // Grenada
-		"ge", // Georgia
-		"gf", // French Guiana
-		"gg", // Guernsey
-		"gh", // Ghana
-		"gi", // Gibraltar
-		"gl", // Greenland
-		"gm", // The Gambia
-		"gn", // Guinea
-		"gp", // Guadeloupe
-		"gq", // Equatorial Guinea
-		"gr", // Greece
-		"gs", // South Georgia and the South Sandwich Islands
-		"gt", // Guatemala
-		"gu", // Guam
-		"gw", // Guinea-Bissau
-		"gy", // Guyana
-		"hk", // Hong Kong
-		"hm", // Heard Island and McDonald Islands
-		"hn", // Honduras
-		"hr", // Croatia (Hrvatska)
-		"ht", // Haiti
-		"hu", // Hungary
-		"id", // Indonesia
-		"ie", // Ireland (Ã‰ire)
-		"il", // Israel
-		"im", // Isle of Man
-		"in", // India
-		"io", // British Indian Ocean Territory
-		"iq", // Iraq
-		"ir", // Iran
-		"is", // Iceland
-		"it", // Italy
-		"je", // Jersey
-		"jm", // Jamaica
-		"jo", // Jordan
-		"jp", // Japan
-		"ke", // Kenya
-		"kg", // Kyrgyzstan
-		"kh", // Cambodia (Khmer)
-		"ki", // Kiribati
-		"km", // Comoros
-		"kn", // Saint Kitts and Nevis
-		"kp", // North Korea
-		"kr", // South Korea
-		"kw", // Kuwait
-		"ky", // Cayman Islands
-		"kz", // Kazakhstan
-		"la", // Laos (currently being marketed as the official domain for Los Angeles)
-		"lb", // Lebanon
-		"lc", // Saint Lucia
-		"li", // Liechtenstein
-		"lk", // Sri Lanka
-		"lr", // Liberia
-		"ls", // Lesotho
-		"lt", // Lithuania
-		"lu", // Luxembourg
-		"lv", // Latvia
-		"ly", // Libya
-		"ma", // Morocco
-		"mc", // Monaco
-		"md", // Moldova
-		"me", // Montenegro
-		"mg", // Madagascar
-		"mh", // Marshall Islands
-		"mk", // Republic of Macedonia
-		"ml", // Mali
-		"mm", // Myanmar
-		"mn", // Mongolia
-		"mo", // Macau
-		"mp", // Northern Mariana Islands
-		"mq", // Martinique
-		"mr", // Mauritania
-		"ms", // Montserrat
-		"mt", // Malta
-		"mu", // Mauritius
-		"mv", // Maldives
-		"mw", // Malawi
-		"mx", // Mexico
-		"my", // Malaysia
-		"mz", // Mozambique
-		"na", // Namibia
-		"nc", // New Caledonia
-		"ne", // Niger
-		"nf", // Norfolk Island
-		"ng", // Nigeria
-		"ni", // Nicaragua
-		"nl", // Netherlands
-		"no", // Norway
-		"np", // Nepal
-		"nr", // Nauru
-		"nu", // Niue
-		"nz", // New Zealand
-		"om", // Oman
-		"pa", // Panama
-		"pe", // Peru
-		"pf", // French Polynesia With Clipperton Island
-		"pg", // Papua New Guinea
-		"ph", // Philippines
-		"pk", // Pakistan
-		"pl", // Poland
-		"pm", // Saint-Pierre and Miquelon
-		"pn", // Pitcairn Islands
-		"pr", // Puerto Rico
-		"ps", // Palestinian territories (PA-controlled West Bank and Gaza Strip)
-		"pt", // Portugal
-		"pw", // Palau
-		"py", // Paraguay
-		"qa", // Qatar
-		"re", // RÃ©union
-		"ro", // Romania
-		"rs", // Serbia
-		"ru", // Russia
-		"rw", // Rwanda
-		"sa", // Saudi Arabia
-		"sb", // Solomon Islands
-		"sc", // Seychelles
-		"sd", // Sudan
-		"se", // Sweden
-		"sg", // Singapore
-		"sh", // Saint Helena
-		"si", // Slovenia
-		"sj", // Svalbard and Jan Mayen Islands Not in use (Norwegian dependencies; see .no)
-		"sk", // Slovakia
-		"sl", // Sierra Leone
-		"sm", // San Marino
-		"sn", // Senegal
-		"so", // Somalia
-		"sr", // Suriname
-		"st", // SÃ£o TomÃ© and PrÃ­ncipe
-		"su", // Soviet Union (deprecated)
-		"sv", // El Salvador
-		"sy", // Syria
-		"sz", // Swaziland
-		"tc", // Turks and Caicos Islands
-		"td", // Chad
-		"tf", // French Southern and Antarctic Lands
-		"tg", // Togo
-		"th", // Thailand
-		"tj", // Tajikistan
-		"tk", // Tokelau
-		"tl", // East Timor (deprecated old code)
-		"tm", // Turkmenistan
-		"tn", // Tunisia
-		"to", // Tonga
-		"tp", // East Timor
-		"tr", // Turkey
-		"tt", // Trinidad and Tobago
-		"tv", // Tuvalu
-		"tw", // Taiwan, Republic of China
-		"tz", // Tanzania
-		"ua", // Ukraine
-		"ug", // Uganda
-		"uk", // United Kingdom
-		"um", // United States Minor Outlying Islands
-		"us", // United States of America
-		"uy", // Uruguay
-		"uz", // Uzbekistan
-		"va", // Vatican City State
-		"vc", // Saint Vincent and the Grenadines
-		"ve", // Venezuela
-		"vg", // British Virgin Islands
-		"vi", // U.S. Virgin Islands
-		"vn", // Vietnam
-		"vu", // Vanuatu
-		"wf", // Wallis and Futuna
-		"ws", // Samoa (formerly Western Samoa)
-		"ye", // Yemen
-		"yt", // Mayotte
-		"yu", // Serbia and Montenegro (originally Yugoslavia)
-		"za", // South Africa
-		"zm", // Zambia
-		"zw", // Zimbabwe
+// tldKey returns tld in lower case without a leading dot, converting an
+// internationalized TLD such as рф to its A-label.
+func tldKey(tld string) string {
+	tld = strings.TrimPrefix(tld, ".")
+	if !isASCII(tld) {
+		if ascii, err := idnaProfile.ToASCII(tld); err == nil {
+			tld = ascii
+		}
 	}
+	return strings.ToLower(tld)
+}
 
+func init() {
 	LOCAL_TLDS = []string{
 		"localhost",   // RFC2606 defined
 		"localdomain", // Also widely used as localhost.localdomain
 	}
+
+	infrastructureTlds = newTldSet(INFRASTRUCTURE_TLDS)
+	genericTlds = newTldSet(GENERIC_TLDS)
+	countryCodeTlds = newTldSet(COUNTRY_CODE_TLDS)
+	localTlds = newTldSet(LOCAL_TLDS)
 }
@@ -19,26 +19,29 @@
 package domainvalidator
 
 import (
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestValidDomains(t *testing.T) {
 	validDomains := []string{
-		`apache.org`,         // apache.org should validate
-		`www.google.com`,     // www.google.com should validate
-		`test-domain.com`,    // test-domain.com should validate
-		`test---domain.com`,  // test---domain.com should validate
-		`test-d-o-m-ain.com`, // test-d-o-m-ain.com should validate
-		`as.uk`,              // two-letter domain label should validate
-		`ApAchE.Org`,         // case-insensitive ApAchE.Org should validate
-		`z.com`,              // single-character domain label should validate
+		`apache.org`,                    // apache.org should validate
+		`www.google.com`,                // www.google.com should validate
+		`test-domain.com`,               // test-domain.com should validate
+		`test---domain.com`,             // test---domain.com should validate
+		`test-d-o-m-ain.com`,            // test-d-o-m-ain.com should validate
+		`as.uk`,                         // two-letter domain label should validate
+		`ApAchE.Org`,                    // case-insensitive ApAchE.Org should validate
+		`z.com`,                         // single-character domain label should validate
 		`i.have.an-example.domain.name`, // i.have.an-example.domain.name should validate
 	}
 	for _, domain := range validDomains {
 		valid := IsValid(domain)
 
 		if !valid {
-			t.Errorf("expected valid domain:", domain)
+			t.Errorf("expected valid domain: %s", domain)
 		}
 	}
 }
@@ -54,18 +57,70 @@ func TestInvalidDomains(t *testing.T) {
 		`c--.com`,               // domain name ending with multiple dashes shouldn't validate
 		`apache.rog`,            // domain name with invalid TLD shouldn't validate
 		`http://www.apache.org`, // URL shouldn't validate
-		` `, // Empty string shouldn't validate as domain name
+		` `,                     // Empty string shouldn't validate as domain name
 		////``,    assertFalse("Null shouldn't validate as domain name", validator.isValid(null));
 	}
 	for _, domain := range invalidDomains {
 		valid := IsValid(domain)
 
 		if valid {
-			t.Errorf("expected invalid domain:", domain)
+			t.Errorf("expected invalid domain: %s", domain)
 		}
 	}
 }
 
+/**
+ * Tests that every label, not just the last one before the TLD, is
+ * checked, and the limits on label and domain name length.
+ */
+func TestLabelRules(t *testing.T) {
+	label63 := strings.Repeat(`a`, 63)
+	// 253 octets, the longest domain name
+	domain253 := label63 + `.` + label63 + `.` + label63 + `.` + strings.Repeat(`b`, 57) + `.com`
+
+	validDomains := []string{
+		domain253,
+		label63 + `.com`,
+		`a.b.c.d.apache.org`,
+		`a-b--c.apache.org`, // -- outside the third and fourth positions
+		`xn--bcher-kva.ch`,  // A-labels may have -- in the third and fourth positions
+		`XN--BCHER-KVA.ch`,
+	}
+	for _, domain := range validDomains {
+		if !IsValid(domain) {
+			t.Errorf("expected valid domain: %s", domain)
+		}
+	}
+
+	invalidDomains := []string{
+		`-bad.good.com`,
+		`bad-.good.com`,
+		`good.-bad.apache.org`,
+		`good.bad-.apache.org`,
+		`ab--cd.com`,       // reserved label
+		`www.ab--cd.com`,   // reserved label
+		`www.apache.ab--c`, // reserved TLD
+		label63 + `a.com`,
+		`www.` + label63 + `a.apache.org`,
+		`c` + domain253,
+	}
+	for _, domain := range invalidDomains {
+		if IsValid(domain) {
+			t.Errorf("expected invalid domain: %s", domain)
+		}
+	}
+
+	allowLocal := New(AllowLocal(true))
+	for _, hostname := range []string{`-host`, `host-`, `ab--cd`, label63 + `a`} {
+		if allowLocal.IsValid(hostname) {
+			t.Errorf("expected invalid hostname: %s", hostname)
+		}
+	}
+	if !allowLocal.IsValid(label63) {
+		t.Errorf("expected valid hostname: %s", label63)
+	}
+}
+
 func TestValidTopLevelDomains(t *testing.T) {
 	// infrastructure TLDs
 	validInfrastructureTopLevelDomains := []string{
@@ -75,7 +130,7 @@ func TestValidTopLevelDomains(t *testing.T) {
 		valid := IsValidInfrastructureTld(domain)
 
 		if !valid {
-			t.Errorf("expected valid instrastructure tld:", domain)
+			t.Errorf("expected valid instrastructure tld: %s", domain)
 		}
 	}
 
@@ -87,7 +142,7 @@ func TestValidTopLevelDomains(t *testing.T) {
 		valid := IsValidGenericTld(domain)
 
 		if !valid {
-			t.Errorf("expected valid generic tld:", domain)
+			t.Errorf("expected valid generic tld: %s", domain)
 		}
 	}
 
@@ -99,7 +154,7 @@ func TestValidTopLevelDomains(t *testing.T) {
 		valid := IsValidCountryCodeTld(domain)
 
 		if !valid {
-			t.Errorf("expected valid country code tld:", domain)
+			t.Errorf("expected valid country code tld: %s", domain)
 		}
 	}
 
@@ -107,12 +162,15 @@ func TestValidTopLevelDomains(t *testing.T) {
 	validTopLevelDomains := []string{
 		`.COM`, // .COM should validate as TLD
 		`.BiZ`, // .BiZ should validate as TLD"
+		`.app`, // gTLDs delegated after 2013 should validate
+		`.dev`,
+		`.xn--p1ai`, // IDN ccTLD .рф should validate
 	}
 	for _, domain := range validTopLevelDomains {
 		valid := IsValidTld(domain)
 
 		if !valid {
-			t.Errorf("expected valid tld:", domain)
+			t.Errorf("expected valid tld: %s", domain)
 		}
 	}
 }
@@ -126,7 +184,7 @@ func TestInvalidTopLevelDomains(t *testing.T) {
 		valid := IsValidInfrastructureTld(domain)
 
 		if valid {
-			t.Errorf("expected invalid instrastructure tld:", domain)
+			t.Errorf("expected invalid instrastructure tld: %s", domain)
 		}
 	}
 
@@ -138,7 +196,7 @@ func TestInvalidTopLevelDomains(t *testing.T) {
 		valid := IsValidGenericTld(domain)
 
 		if valid {
-			t.Errorf("expected invalid generic tld:", domain)
+			t.Errorf("expected invalid generic tld: %s", domain)
 		}
 	}
 
@@ -150,7 +208,7 @@ func TestInvalidTopLevelDomains(t *testing.T) {
 		valid := IsValidCountryCodeTld(domain)
 
 		if valid {
-			t.Errorf("expected invalid country code tld:", domain)
+			t.Errorf("expected invalid country code tld: %s", domain)
 		}
 	}
 
@@ -158,49 +216,379 @@ func TestInvalidTopLevelDomains(t *testing.T) {
 	invalidTopLevelDomains := []string{
 		`.nope`, // invalid TLD shouldn't validate
 		``,      // empty string shouldn't validate as TLD
+		`.yu`,   // retired ccTLDs shouldn't validate
+		`.tp`,
+		`.an`,
 		//assertFalse("null shouldn't validate as TLD", validator.isValid(null));
 	}
 	for _, domain := range invalidTopLevelDomains {
 		valid := IsValidTld(domain)
 
 		if valid {
-			t.Errorf("expected invalid tld:", domain)
-		}
-	}
-}
-
-// TODO
-//public void testAllowLocal() {
-//   DomainValidator noLocal = DomainValidator.getInstance(false);
-//   DomainValidator allowLocal = DomainValidator.getInstance(true);
-//
-//   // Default is false, and should use singletons
-//   assertEquals(noLocal, validator);
-//
-//   // Default won't allow local
-//   assertFalse("localhost.localdomain should validate", noLocal.isValid("localhost.localdomain"));
-//   assertFalse("localhost should validate", noLocal.isValid("localhost"));
-//
-//   // But it may be requested
-//   assertTrue("localhost.localdomain should validate", allowLocal.isValid("localhost.localdomain"));
-//   assertTrue("localhost should validate", allowLocal.isValid("localhost"));
-//   assertTrue("hostname should validate", allowLocal.isValid("hostname"));
-//   assertTrue("machinename should validate", allowLocal.isValid("machinename"));
-//
-//   // Check the localhost one with a few others
-//   assertTrue("apache.org should validate", allowLocal.isValid("apache.org"));
-//   assertFalse("domain name with spaces shouldn't validate", allowLocal.isValid(" apache.org "));
-//}
-
-//func TestIDN(t *testing.T) {
-//	// infrastructure TLDs
-//	validIDNs := []string{
-//		`www.xn--bcher-kva.ch`, // b\u00fccher.ch in IDN should validate
-//	}
-//	for _, domain := range validIDNs {
-//		valid := IsValid(domain)
-//
-//		if !valid {
-//			t.Errorf("expected valid IDN", domain)
-//		}
-//	}
+			t.Errorf("expected invalid tld: %s", domain)
+		}
+	}
+}
+
+func TestAllowLocal(t *testing.T) {
+	noLocal := New(AllowLocal(false))
+	allowLocal := New(AllowLocal(true))
+
+	// Default won't allow local
+	localDomains := []string{
+		`localhost.localdomain`,
+		`localhost`,
+	}
+	for _, domain := range localDomains {
+		if noLocal.IsValid(domain) {
+			t.Errorf("expected local domain to be rejected: %s", domain)
+		}
+		if IsValid(domain) {
+			t.Errorf("expected local domain to be rejected by default: %s", domain)
+		}
+	}
+
+	// But it may be requested
+	validDomains := []string{
+		`localhost.localdomain`,
+		`localhost`,
+		`hostname`,
+		`machinename`,
+		`build-01`,
+		`apache.org`, // Check the localhost one with a few others
+	}
+	for _, domain := range validDomains {
+		if !allowLocal.IsValid(domain) {
+			t.Errorf("expected valid local domain: %s", domain)
+		}
+	}
+
+	invalidDomains := []string{
+		` apache.org `, // domain name with spaces shouldn't validate
+		`-hostname`,
+		`hostname-`,
+		`host name`,
+		`hostname.rog`,
+		``,
+	}
+	for _, domain := range invalidDomains {
+		if allowLocal.IsValid(domain) {
+			t.Errorf("expected invalid local domain: %s", domain)
+		}
+	}
+
+	if !allowLocal.IsValidTld(`.localdomain`) {
+		t.Errorf("expected valid local tld: %s", `.localdomain`)
+	}
+	if noLocal.IsValidTld(`.localdomain`) {
+		t.Errorf("expected invalid local tld: %s", `.localdomain`)
+	}
+}
+
+func TestIDN(t *testing.T) {
+	validIDNs := []string{
+		`www.xn--bcher-kva.ch`,  // b\u00fccher.ch in IDN should validate
+		`www.bücher.ch`,         // Unicode form of the above should validate
+		`MÜNCHEN.DE`,            // mapped to lower case before conversion
+		`例え.jp`,                 // Japanese label should validate
+		`例え。jp`,                 // ideographic full stop separates labels
+		`пример.рф`,             // IDN ccTLD .рф should validate
+		`xn--e1afmkfd.xn--p1ai`, // A-label form of the above should validate
+		`例子.公司`,                 // IDN gTLD should validate
+	}
+	for _, domain := range validIDNs {
+		if !IsValid(domain) {
+			t.Errorf("expected valid IDN: %s", domain)
+		}
+	}
+
+	invalidIDNs := []string{
+		`www.xn--zz.ch`,     // A-label that doesn't decode
+		`www.xn--ab-tha.ch`, // A-label of "Øab", whose U-label must be lower case
+		`bü cher.ch`,        // space is disallowed
+		`bücher.nope`,       // unknown TLD
+		`bücher.рфx`,        // unknown IDN TLD
+		`www.bücher..ch`,    // empty label
+	}
+	for _, domain := range invalidIDNs {
+		if IsValid(domain) {
+			t.Errorf("expected invalid IDN: %s", domain)
+		}
+	}
+
+	if !IsValidTld(`.рф`) || !IsValidCountryCodeTld(`рф`) {
+		t.Errorf("expected valid IDN ccTLD: %s", `.рф`)
+	}
+	if !IsValidGenericTld(`.公司`) {
+		t.Errorf("expected valid IDN gTLD: %s", `.公司`)
+	}
+
+	local := New(AllowLocal(true))
+	if !local.IsValid(`bücher`) {
+		t.Errorf("expected valid IDN hostname: %s", `bücher`)
+	}
+
+	validator := New()
+	validator.UpdateTLDOverride(COUNTRY_CODE_MINUS, []string{`рф`})
+	if validator.IsValid(`пример.рф`) || validator.IsValid(`xn--e1afmkfd.xn--p1ai`) {
+		t.Errorf("expected IDN TLD override to apply to both forms")
+	}
+}
+
+func TestUnicodeToASCII(t *testing.T) {
+	tests := map[string]string{
+		`www.apache.org`: `www.apache.org`,
+		`рф`:             `xn--p1ai`,
+		`münchen.de`:     `xn--mnchen-3ya.de`,
+		"bü\x00cher.ch":  "bü\x00cher.ch", // conversion fails
+	}
+	for input, want := range tests {
+		if got := UnicodeToASCII(input); got != want {
+			t.Errorf("UnicodeToASCII(%q) = %q, expected %q", input, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		domain  string
+		ascii   string
+		unicode string
+	}{
+		{`münchen.de`, `xn--mnchen-3ya.de`, `münchen.de`},
+		{`XN--MNCHEN-3YA.DE`, `xn--mnchen-3ya.de`, `münchen.de`},
+		{`例え。jp`, `xn--r8jz45g.jp`, `例え.jp`},
+		{`пример.рф`, `xn--e1afmkfd.xn--p1ai`, `пример.рф`},
+		{`www.Apache.org`, `www.apache.org`, `www.apache.org`},
+	}
+	for _, tt := range tests {
+		d, err := Parse(tt.domain)
+		if err != nil {
+			t.Errorf("Parse(%s) returned %v", tt.domain, err)
+			continue
+		}
+		if d.ASCII != tt.ascii || d.Unicode != tt.unicode {
+			t.Errorf("Parse(%s) = %+v, expected {ASCII:%s Unicode:%s}", tt.domain, d, tt.ascii, tt.unicode)
+		}
+	}
+
+	if _, err := Parse(`bücher.nope`); !errors.Is(err, ErrUnknownTLD) {
+		t.Errorf("Parse(%s) returned %v, expected %v", `bücher.nope`, err, ErrUnknownTLD)
+	}
+}
+
+/**
+ * Tests that Validate reports the reason, segment and offset.
+ */
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		domain  string
+		err     error
+		segment string
+		offset  int
+	}{
+		{`apache.org`, nil, ``, 0},
+		{``, ErrEmpty, ``, 0},
+		{`apache.rog`, ErrUnknownTLD, `rog`, 7},
+		{`www.apache.c`, ErrUnknownTLD, `c`, 11},
+		{`apa che.org`, ErrInvalidDomain, `apa che`, 0},
+		{`www.-apache.org`, ErrInvalidDomain, `-apache`, 4},
+		{`www..org`, ErrInvalidDomain, ``, 4},
+		{`apache.org.`, ErrInvalidDomain, ``, 11},
+		{`localhost`, ErrInvalidDomain, `localhost`, 0},
+		{`www.` + strings.Repeat(`a`, 64) + `.org`, ErrLabelTooLong, strings.Repeat(`a`, 64), 4},
+		{`bücher.nope`, ErrUnknownTLD, `nope`, 8},
+		{`例え。nope`, ErrUnknownTLD, `nope`, 9},
+		{`www.xn--zz.ch`, ErrInvalidDomain, `xn--zz`, 4},
+		{`-bad.good.com`, ErrInvalidDomain, `-bad`, 0},
+		{`www.ab--cd.com`, ErrInvalidDomain, `ab--cd`, 4},
+		{strings.Repeat(`a.`, 127) + `com`, ErrDomainTooLong, strings.Repeat(`a.`, 127) + `com`, 0},
+		{`www.` + strings.Repeat(`ü`, 60) + `.de`, ErrLabelTooLong, strings.Repeat(`ü`, 60), 4},
+	}
+	for _, test := range tests {
+		err := Validate(test.domain)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, expected nil", test.domain, err)
+			}
+			continue
+		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("Validate(%q) = %v, expected %v", test.domain, err, test.err)
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Validate(%q) = %T, expected *ValidationError", test.domain, err)
+			continue
+		}
+		if verr.Segment != test.segment || verr.Offset != test.offset {
+			t.Errorf("Validate(%q) = %q at %d, expected %q at %d",
+				test.domain, verr.Segment, verr.Offset, test.segment, test.offset)
+		}
+	}
+
+	if err := New(AllowLocal(true)).Validate(`localhost`); err != nil {
+		t.Errorf("Validate(%q) = %v, expected nil", `localhost`, err)
+	}
+}
+
+func BenchmarkIsValid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValid(`i.have.an-example.domain.name`)
+	}
+}
+
+func BenchmarkIsValidInvalid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValid(`apache.rog`)
+	}
+}
+
+// A mix of TLDs from the start, middle and end of the tables, and misses
+var benchmarkTlds = []string{`.com`, `ac`, `museum`, `jp`, `zw`, `ARPA`, `nope`, `rog`}
+
+func BenchmarkIsValidTld(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValidTld(benchmarkTlds[i%len(benchmarkTlds)])
+	}
+}
+
+// linearContains is the linear scan the TLD sets replaced, kept as a
+// baseline for BenchmarkIsValidTld.
+func linearContains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
+func BenchmarkIsValidTldLinearScan(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tld := strings.TrimPrefix(strings.ToLower(benchmarkTlds[i%len(benchmarkTlds)]), ".")
+		_ = linearContains(INFRASTRUCTURE_TLDS, tld) || linearContains(GENERIC_TLDS, tld) ||
+			linearContains(COUNTRY_CODE_TLDS, tld)
+	}
+}
+
+func TestUpdateTLDOverride(t *testing.T) {
+	validator := New()
+	if validator.IsValid(`www.apache.nope`) {
+		t.Errorf("expected invalid domain: %s", `www.apache.nope`)
+	}
+
+	if err := validator.UpdateTLDOverride(GENERIC_PLUS, []string{`NOPE`, `.corp`}); err != nil {
+		t.Fatalf("UpdateTLDOverride(GENERIC_PLUS) = %v", err)
+	}
+	if err := validator.UpdateTLDOverride(COUNTRY_CODE_PLUS, []string{`zz`}); err != nil {
+		t.Fatalf("UpdateTLDOverride(COUNTRY_CODE_PLUS) = %v", err)
+	}
+	if err := validator.UpdateTLDOverride(GENERIC_MINUS, []string{`com`}); err != nil {
+		t.Fatalf("UpdateTLDOverride(GENERIC_MINUS) = %v", err)
+	}
+	if err := validator.UpdateTLDOverride(COUNTRY_CODE_MINUS, []string{`.UK`}); err != nil {
+		t.Fatalf("UpdateTLDOverride(COUNTRY_CODE_MINUS) = %v", err)
+	}
+
+	validDomains := []string{
+		`www.apache.nope`,
+		`www.apache.corp`,
+		`www.apache.zz`,
+		`www.apache.org`,
+	}
+	for _, domain := range validDomains {
+		if !validator.IsValid(domain) {
+			t.Errorf("expected valid domain with overrides: %s", domain)
+		}
+	}
+	invalidDomains := []string{
+		`www.apache.com`,
+		`www.apache.uk`,
+	}
+	for _, domain := range invalidDomains {
+		if validator.IsValid(domain) {
+			t.Errorf("expected invalid domain with overrides: %s", domain)
+		}
+	}
+	if !validator.IsValidGenericTld(`.nope`) || validator.IsValidCountryCodeTld(`.nope`) {
+		t.Errorf("expected .nope to be a generic TLD only")
+	}
+	if !validator.IsValidCountryCodeTld(`.zz`) || validator.IsValidGenericTld(`.zz`) {
+		t.Errorf("expected .zz to be a country code TLD only")
+	}
+
+	// Other validators, and the package level functions, are unaffected
+	for _, domain := range []string{`www.apache.nope`, `www.apache.zz`} {
+		if IsValid(domain) || New().IsValid(domain) {
+			t.Errorf("expected override to be scoped to its validator: %s", domain)
+		}
+	}
+	for _, domain := range []string{`www.apache.com`, `www.apache.uk`} {
+		if !IsValid(domain) || !New().IsValid(domain) {
+			t.Errorf("expected override to be scoped to its validator: %s", domain)
+		}
+	}
+
+	entries := validator.GetTLDEntries(GENERIC_PLUS)
+	if len(entries) != 2 || entries[0] != `corp` || entries[1] != `nope` {
+		t.Errorf("GetTLDEntries(GENERIC_PLUS) = %v, expected [corp nope]", entries)
+	}
+
+	// Updating replaces the previous entries
+	if err := validator.UpdateTLDOverride(GENERIC_PLUS, []string{}); err != nil {
+		t.Fatalf("UpdateTLDOverride(GENERIC_PLUS) = %v", err)
+	}
+	if validator.IsValid(`www.apache.nope`) {
+		t.Errorf("expected invalid domain after reset: %s", `www.apache.nope`)
+	}
+	if entries := validator.GetTLDEntries(GENERIC_PLUS); len(entries) != 0 {
+		t.Errorf("GetTLDEntries(GENERIC_PLUS) = %v, expected []", entries)
+	}
+
+	if err := validator.UpdateTLDOverride(ArrayType(-1), []string{`nope`}); err == nil {
+		t.Errorf("expected error for unknown table")
+	}
+	if entries := validator.GetTLDEntries(ArrayType(99)); entries != nil {
+		t.Errorf("GetTLDEntries(99) = %v, expected nil", entries)
+	}
+}
+
+func TestUpdateTLDOverrideConcurrent(t *testing.T) {
+	validator := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				validator.UpdateTLDOverride(GENERIC_PLUS, []string{`nope`})
+			}
+		}()
+		// Updates of different tables must not undo each other
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				validator.UpdateTLDOverride(COUNTRY_CODE_PLUS, []string{`zz`})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				validator.IsValid(`www.apache.nope`)
+				validator.GetTLDEntries(GENERIC_PLUS)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, domain := range []string{`www.apache.nope`, `www.apache.zz`} {
+		if !validator.IsValid(domain) {
+			t.Errorf("expected valid domain with overrides: %s", domain)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2013 Doug Sparling. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Command tldgen generates the domainvalidator TLD tables from local
+// copies of IANA's TLD list and root zone database:
+//
+//	curl -o tlds-alpha-by-domain.txt https://data.iana.org/TLD/tlds-alpha-by-domain.txt
+//	curl -o root-zone-db.html https://www.iana.org/domains/root/db
+//	go generate
+//
+// The TLDs come from tlds-alpha-by-domain.txt and their categories from
+// the root zone database. The version line of tlds-alpha-by-domain.txt is
+// required and recorded in the output, so every table can be traced to the
+// IANA release it came from. TLDs added to or removed from each table since
+// the previous output are reported on stderr.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The generated tables, in output order
+var tables = []string{"INFRASTRUCTURE_TLDS", "GENERIC_TLDS", "COUNTRY_CODE_TLDS"}
+
+// Root zone database types and the table each belongs to
+var categories = map[string]string{
+	"infrastructure":     "INFRASTRUCTURE_TLDS",
+	"generic":            "GENERIC_TLDS",
+	"generic-restricted": "GENERIC_TLDS",
+	"sponsored":          "GENERIC_TLDS",
+	"country-code":       "COUNTRY_CODE_TLDS",
+	"test":               "",
+}
+
+// A root zone database row, e.g.
+//
+//	<span class="domain tld"><a href="/domains/root/db/xn--p1ai.html">.рф</a></span></td>
+//	<td>country-code</td>
+var rootDbRow = regexp.MustCompile(`(?s)<a href="/domains/root/db/([^"/]+)\.html">\.?([^<]*)</a>.*?</td>\s*<td>([^<]*)</td>`)
+
+type tld struct {
+	name    string // A-label as used in the tables
+	display string // U-label shown in the root zone database
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("tldgen: ")
+
+	tldsFile := flag.String("tlds", "tlds-alpha-by-domain.txt", "IANA TLD list")
+	rootDbFile := flag.String("rootdb", "root-zone-db.html", "IANA root zone database page")
+	outFile := flag.String("out", "tlds.go", "generated Go file")
+	prevFile := flag.String("prev", "", "Go file holding the previous tables (default: -out)")
+	flag.Parse()
+	if *prevFile == "" {
+		*prevFile = *outFile
+	}
+
+	version, names, err := readTlds(*tldsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	types, displays, err := readRootDb(*rootDbFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	current, err := classify(names, types, displays)
+	if err != nil {
+		log.Fatalf("%s, %s: %v", *tldsFile, *rootDbFile, err)
+	}
+
+	previous, err := readPrevious(*prevFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report(previous, current)
+
+	src, err := generate(version, current)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*outFile, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// classify sorts the TLDs into their tables by the type the root zone
+// database gives them. Test TLDs are left out.
+func classify(names []string, types, displays map[string]string) (map[string][]tld, error) {
+	current := make(map[string][]tld)
+	for _, name := range names {
+		typ, ok := types[name]
+		if !ok {
+			return nil, fmt.Errorf("no category for %s", name)
+		}
+		table, ok := categories[typ]
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q for %s", typ, name)
+		}
+		if table == "" {
+			continue
+		}
+		display := displays[name]
+		if display == name {
+			display = ""
+		}
+		current[table] = append(current[table], tld{name: name, display: display})
+	}
+	return current, nil
+}
+
+// readTlds returns the version comment and the lower case TLDs of
+// tlds-alpha-by-domain.txt, which starts with a line such as
+//
+//	# Version 2024010100, Last Updated Mon Jan  1 07:07:01 2024 UTC
+func readTlds(path string) (version string, names []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			if version == "" {
+				version = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			}
+			continue
+		}
+		if line != "" {
+			names = append(names, strings.ToLower(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	if !strings.HasPrefix(version, "Version ") {
+		return "", nil, fmt.Errorf("%s: no version line", path)
+	}
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("%s: no TLDs found", path)
+	}
+	sort.Strings(names)
+	return version, names, nil
+}
+
+// readRootDb returns the type and displayed name of each TLD in the
+// root zone database page.
+func readRootDb(path string) (types, displays map[string]string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	types = make(map[string]string)
+	displays = make(map[string]string)
+	for _, m := range rootDbRow.FindAllSubmatch(data, -1) {
+		name := strings.ToLower(string(m[1]))
+		types[name] = strings.TrimSpace(string(m[3]))
+		displays[name] = strings.TrimSpace(string(m[2]))
+	}
+	if len(types) == 0 {
+		return nil, nil, fmt.Errorf("%s: no TLDs found", path)
+	}
+	return types, displays, nil
+}
+
+// readPrevious collects the string literals assigned to each table in
+// the Go file at path, whether declared with a value (as in generated
+// output) or assigned in an init function. A missing file yields no
+// previous tables.
+func readPrevious(path string) (map[string][]string, error) {
+	previous := make(map[string][]string)
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if os.IsNotExist(err) {
+		return previous, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	collect := func(name string, value ast.Expr) {
+		lit, ok := value.(*ast.CompositeLit)
+		if !ok {
+			return
+		}
+		for _, elt := range lit.Elts {
+			if s, ok := elt.(*ast.BasicLit); ok && s.Kind == token.STRING {
+				if v, err := strconv.Unquote(s.Value); err == nil {
+					previous[name] = append(previous[name], strings.ToLower(v))
+				}
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					collect(name.Name, n.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && i < len(n.Rhs) {
+					collect(ident.Name, n.Rhs[i])
+				}
+			}
+		}
+		return true
+	})
+	return previous, nil
+}
+
+// report prints the TLDs added to and removed from each table.
+func report(previous map[string][]string, current map[string][]tld) {
+	for _, table := range tables {
+		was := make(map[string]bool)
+		for _, name := range previous[table] {
+			was[name] = true
+		}
+		is := make(map[string]bool)
+		var added, removed []string
+		for _, t := range current[table] {
+			is[t.name] = true
+			if !was[t.name] {
+				added = append(added, t.name)
+			}
+		}
+		for _, name := range previous[table] {
+			if !is[name] {
+				removed = append(removed, name)
+			}
+		}
+		sort.Strings(removed)
+		log.Printf("%s: %d TLDs, %d added, %d removed", table, len(current[table]), len(added), len(removed))
+		if len(added) > 0 {
+			log.Printf("  added:   %s", strings.Join(added, " "))
+		}
+		if len(removed) > 0 {
+			log.Printf("  removed: %s", strings.Join(removed, " "))
+		}
+	}
+}
+
+// generate renders the tables as formatted Go source.
+func generate(version string, current map[string][]tld) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by tldgen; DO NOT EDIT.")
+	fmt.Fprintf(&buf, "// Source: tlds-alpha-by-domain.txt, %s\n", version)
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package domainvalidator")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// ----- TLDs defined by IANA")
+	fmt.Fprintln(&buf, "// ----- Authoritative and comprehensive list at:")
+	fmt.Fprintln(&buf, "// ----- http://data.iana.org/TLD/tlds-alpha-by-domain.txt")
+	for _, table := range tables {
+		fmt.Fprintln(&buf)
+		fmt.Fprintf(&buf, "var %s = []string{\n", table)
+		for _, t := range current[table] {
+			if t.display != "" {
+				fmt.Fprintf(&buf, "\t%q, // %s\n", t.name, t.display)
+			} else {
+				fmt.Fprintf(&buf, "\t%q,\n", t.name)
+			}
+		}
+		fmt.Fprintln(&buf, "}")
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,141 @@
+// Copyright 2013 Doug Sparling. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTlds(t *testing.T) {
+	version, names, err := readTlds("testdata/tlds-alpha-by-domain.txt")
+	if err != nil {
+		t.Fatalf("readTlds: %v", err)
+	}
+	if want := "Version 2024010100, Last Updated Mon Jan  1 07:07:01 2024 UTC"; version != want {
+		t.Errorf("readTlds version: got %q, want %q", version, want)
+	}
+	want := []string{"arpa", "com", "de", "museum", "xn--p1ai", "xn--zckzah"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("readTlds names: got %q, want %q", names, want)
+	}
+
+	dir := t.TempDir()
+	invalid := map[string]string{
+		"no-version.txt": "COM\nDE\n",
+		"no-tlds.txt":    "# Version 2024010100, Last Updated Mon Jan  1 07:07:01 2024 UTC\n",
+	}
+	for name, content := range invalid {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := readTlds(path); err == nil {
+			t.Errorf("readTlds(%s): expected error", name)
+		}
+	}
+}
+
+func TestReadRootDb(t *testing.T) {
+	types, displays, err := readRootDb("testdata/root-zone-db.html")
+	if err != nil {
+		t.Fatalf("readRootDb: %v", err)
+	}
+	wantTypes := map[string]string{
+		"arpa":       "infrastructure",
+		"com":        "generic",
+		"de":         "country-code",
+		"museum":     "sponsored",
+		"xn--p1ai":   "country-code",
+		"xn--zckzah": "test",
+	}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("readRootDb types: got %v, want %v", types, wantTypes)
+	}
+	if got := displays["xn--p1ai"]; got != "рф" {
+		t.Errorf("readRootDb display of xn--p1ai: got %q, want %q", got, "рф")
+	}
+	if got := displays["com"]; got != "com" {
+		t.Errorf("readRootDb display of com: got %q, want %q", got, "com")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	names := []string{"com", "de", "xn--zckzah"}
+	types := map[string]string{"com": "generic", "de": "country-code", "xn--zckzah": "test"}
+	current, err := classify(names, types, map[string]string{"com": "com"})
+	if err != nil {
+		t.Fatalf("classify: %v", err)
+	}
+	want := map[string][]tld{
+		"GENERIC_TLDS":      {{name: "com"}},
+		"COUNTRY_CODE_TLDS": {{name: "de"}},
+	}
+	if !reflect.DeepEqual(current, want) {
+		t.Errorf("classify: got %v, want %v", current, want)
+	}
+
+	if _, err := classify([]string{"org"}, types, nil); err == nil {
+		t.Errorf("classify: expected error for a TLD without category")
+	}
+	if _, err := classify([]string{"com"}, map[string]string{"com": "unknown"}, nil); err == nil {
+		t.Errorf("classify: expected error for an unknown category")
+	}
+}
+
+/**
+ * Test the generated source against testdata/tlds.golden, and that
+ * readPrevious reads the tables back from it.
+ */
+func TestGenerate(t *testing.T) {
+	version, names, err := readTlds("testdata/tlds-alpha-by-domain.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, displays, err := readRootDb("testdata/root-zone-db.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := classify(names, types, displays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(version, current)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	golden, err := os.ReadFile("testdata/tlds.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != string(golden) {
+		t.Errorf("generate: got\n%s\nwant\n%s", src, golden)
+	}
+	if !strings.Contains(string(src), "// Source: tlds-alpha-by-domain.txt, "+version+"\n") {
+		t.Errorf("generate: no source line for %q", version)
+	}
+
+	previous, err := readPrevious("testdata/tlds.golden")
+	if err != nil {
+		t.Fatalf("readPrevious: %v", err)
+	}
+	for _, table := range tables {
+		var want []string
+		for _, tl := range current[table] {
+			want = append(want, tl.name)
+		}
+		if !reflect.DeepEqual(previous[table], want) {
+			t.Errorf("readPrevious %s: got %q, want %q", table, previous[table], want)
+		}
+	}
+
+	if previous, err := readPrevious("testdata/missing.go"); err != nil || len(previous) != 0 {
+		t.Errorf("readPrevious of a missing file: got %v, %v", previous, err)
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright 2013 Doug Sparling. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emailvalidator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+var (
+	ErrInvalidDisplayName = errors.New("emailvalidator: invalid display name")
+	ErrInvalidMailbox     = errors.New("emailvalidator: invalid mailbox")
+)
+
+/**
+ * ListError reports which element of an address list failed
+ * validation. Index counts the mailboxes of the list from zero, and
+ * the offset in Err is relative to the whole list.
+ */
+type ListError struct {
+	Index int
+	Err   *ValidationError
+}
+
+func (e *ListError) Error() string {
+	return fmt.Sprintf("element %d: %v", e.Index, e.Err)
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * Mailbox is an RFC 5322 mailbox: an address with an optional display
+ * name. Name has its quotes, comments and folding removed. Group is the
+ * name of the group the mailbox was listed in, if any.
+ */
+type Mailbox struct {
+	Name    string
+	Address Address
+	Group   string
+}
+
+/**
+ * Parses a mailbox, such as "Jane Doe" <jane@example.com> or a bare
+ * address, using the default validator.
+ * @param mailbox The value validation is being performed on.
+ * @return the mailbox, and nil if it is valid, otherwise a
+ * *ValidationError.
+ */
+func ParseMailbox(mailbox string) (Mailbox, error) {
+	return defaultValidator.ParseMailbox(mailbox)
+}
+
+/**
+ * Parses an RFC 5322 mailbox: a name-addr, such as
+ * "Jane Doe" <jane@example.com>, or a bare addr-spec. The address is
+ * validated as by Validate.
+ * @param mailbox The value validation is being performed on.
+ * @return the mailbox, and nil if it is valid, otherwise a
+ * *ValidationError.
+ */
+func (v *EmailValidator) ParseMailbox(mailbox string) (Mailbox, error) {
+	if strings.TrimSpace(mailbox) == "" {
+		return Mailbox{}, &ValidationError{Err: ErrEmpty}
+	}
+	m, err := v.parseMailbox(mailbox, 0)
+	if err != nil {
+		return Mailbox{}, err
+	}
+	return m, nil
+}
+
+/**
+ * Parses an address list using the default validator.
+ * @param addressList The value validation is being performed on.
+ * @return the mailboxes of the list, and nil if it is valid, otherwise
+ * a *ListError.
+ */
+func ParseAddressList(addressList string) ([]Mailbox, error) {
+	return defaultValidator.ParseAddressList(addressList)
+}
+
+/**
+ * Parses an RFC 5322 address-list, such as
+ * "Jane Doe" <jane@example.com>, bob@example.org. Elements are
+ * mailboxes or groups (Team: a@example.com, b@example.com;), and
+ * the address of each mailbox is validated as by Validate. The
+ * obsolete empty list elements are not accepted.
+ * @param addressList The value validation is being performed on.
+ * @return the mailboxes of the list, and nil if it is valid, otherwise
+ * a *ListError.
+ */
+func (v *EmailValidator) ParseAddressList(addressList string) ([]Mailbox, error) {
+	var mailboxes []Mailbox
+	listError := func(err *ValidationError) error {
+		return &ListError{Index: len(mailboxes), Err: err}
+	}
+
+	group, groupStart, inGroup, groupEmpty := "", 0, false, false
+	for pos := 0; ; {
+		delims := ",:"
+		if inGroup {
+			delims = ",;"
+		}
+		end, err := nextDelim(addressList, pos, delims)
+		if err != nil {
+			return nil, listError(err)
+		}
+		element := addressList[pos:end]
+
+		if end < len(addressList) && addressList[end] == ':' {
+			// display-name ":" [group-list] ";" [CFWS]
+			name, err := v.parsePhrase(element, pos, false)
+			if err != nil {
+				return nil, listError(err)
+			}
+			group, groupStart, inGroup, groupEmpty = name, pos, true, true
+			pos = end + 1
+			continue
+		}
+
+		if v.isCFWS(element) && inGroup && groupEmpty && end < len(addressList) && addressList[end] == ';' {
+			// An empty group, such as undisclosed-recipients:;
+		} else if v.isCFWS(element) {
+			return nil, listError(&ValidationError{Err: ErrEmpty, Offset: pos})
+		} else {
+			m, err := v.parseMailbox(element, pos)
+			if err != nil {
+				return nil, listError(err)
+			}
+			m.Group = group
+			mailboxes = append(mailboxes, m)
+			groupEmpty = false
+		}
+
+		if end == len(addressList) {
+			if inGroup {
+				return nil, listError(&ValidationError{Err: ErrInvalidMailbox, Segment: addressList[groupStart:], Offset: groupStart})
+			}
+			return mailboxes, nil
+		}
+		pos = end + 1
+		if addressList[end] == ';' {
+			group, inGroup = "", false
+			// Only CFWS may follow the group, up to the next element
+			end, err = nextDelim(addressList, pos, ",")
+			if err != nil {
+				return nil, listError(err)
+			}
+			if !v.isCFWS(addressList[pos:end]) {
+				return nil, listError(&ValidationError{Err: ErrInvalidMailbox, Segment: addressList[pos:end], Offset: pos})
+			}
+			if end == len(addressList) {
+				return mailboxes, nil
+			}
+			pos = end + 1
+		}
+	}
+}
+
+/**
+ * Validates an address list using the default validator.
+ * @param addressList The value validation is being performed on.
+ * @return nil if the list is valid, otherwise a *ListError.
+ */
+func ValidateAddressList(addressList string) error {
+	return defaultValidator.ValidateAddressList(addressList)
+}
+
+/**
+ * Validates an RFC 5322 address-list, see ParseAddressList.
+ * @param addressList The value validation is being performed on.
+ * @return nil if the list is valid, otherwise a *ListError.
+ */
+func (v *EmailValidator) ValidateAddressList(addressList string) error {
+	_, err := v.ParseAddressList(addressList)
+	return err
+}
+
+/*
+ * parseMailbox parses
+ *
+ *	mailbox    = name-addr / addr-spec
+ *	name-addr  = [display-name] angle-addr
+ *	angle-addr = [CFWS] "<" addr-spec ">" [CFWS]
+ *
+ * found at offset base of the input.
+ */
+func (v *EmailValidator) parseMailbox(mailbox string, base int) (Mailbox, *ValidationError) {
+	lt, err := nextDelim(mailbox, 0, "<")
+	if err != nil {
+		err.Offset += base
+		return Mailbox{}, err
+	}
+	if lt == len(mailbox) {
+		addr, err := v.parse(mailbox)
+		if err != nil {
+			err.Offset += base
+			return Mailbox{}, err
+		}
+		return Mailbox{Address: newAddress(addr)}, nil
+	}
+
+	name, err := v.parsePhrase(mailbox[:lt], base, true)
+	if err != nil {
+		return Mailbox{}, err
+	}
+	gt := skipNested(mailbox, lt)
+	if gt < 0 {
+		return Mailbox{}, &ValidationError{Err: ErrInvalidMailbox, Segment: mailbox[lt:], Offset: base + lt}
+	}
+	if rest := mailbox[gt:]; !v.isCFWS(rest) {
+		return Mailbox{}, &ValidationError{Err: ErrInvalidMailbox, Segment: rest, Offset: base + gt}
+	}
+	addr, err := v.parse(mailbox[lt+1 : gt-1])
+	if err != nil {
+		err.Offset += base + lt + 1
+		return Mailbox{}, err
+	}
+	return Mailbox{Name: name, Address: newAddress(addr)}, nil
+}
+
+/*
+ * parsePhrase parses a display name, found at offset base of the input,
+ * and returns it without quotes, comments and folding:
+ *
+ *	display-name = phrase
+ *	phrase       = 1*word / obs-phrase
+ *	word         = atom / quoted-string
+ *	obs-phrase   = word *(word / "." / CFWS)
+ *
+ * The obsolete form is accepted for names such as John Q. Public. If
+ * optional is set, phrase may also be empty or hold only CFWS.
+ */
+func (v *EmailValidator) parsePhrase(phrase string, base int, optional bool) (string, *ValidationError) {
+	if err := checkChars(phrase, v.allowUTF8, true); err != nil {
+		err.Offset += base
+		return "", err
+	}
+
+	var name strings.Builder
+	space := false
+	p := &addrSpecParser{s: phrase}
+	for words := 0; ; words++ {
+		start := p.pos
+		if err := p.cfws(ErrInvalidDisplayName); err != nil {
+			err.Offset += base
+			return "", err
+		}
+		space = space || p.pos > start
+		if p.pos == len(phrase) {
+			if words == 0 && !optional {
+				return "", &ValidationError{Err: ErrInvalidDisplayName, Segment: phrase, Offset: base}
+			}
+			return name.String(), nil
+		}
+		if space && name.Len() > 0 {
+			name.WriteByte(' ')
+		}
+		space = false
+
+		start = p.pos
+		switch c := phrase[p.pos]; {
+		case c == '"':
+			if err := p.quotedString(ErrInvalidDisplayName); err != nil {
+				err.Offset += base
+				return "", err
+			}
+			name.WriteString(unquotePairs(phrase[start+1 : p.pos-1]))
+		case isAtext(c) || c == '.' && words > 0:
+			for p.pos < len(phrase) && (isAtext(phrase[p.pos]) || phrase[p.pos] == '.') {
+				p.pos++
+			}
+			name.WriteString(phrase[start:p.pos])
+		default:
+			err := p.errorAt(ErrInvalidDisplayName)
+			err.Offset += base
+			return "", err
+		}
+	}
+}
+
+// newAddress builds the Address that Parse returns from spec.
+func newAddress(spec addrSpec) Address {
+	addr := Address{
+		LocalPart:   spec.localPart,
+		Domain:      spec.domain,
+		IsIPLiteral: spec.literal,
+		Quoted:      spec.quoted,
+	}
+	if spec.literal {
+		ipAddress := spec.domain[1 : len(spec.domain)-1]
+		if hasIPv6Tag(ipAddress) {
+			ipAddress = ipAddress[len(IPV6_TAG):]
+		}
+		addr.IP = net.ParseIP(ipAddress)
+	}
+	return addr
+}
+
+// unquotePairs returns the content of a quoted string with its quoted
+// pairs replaced by the character they escape and folding removed.
+func unquotePairs(content string) string {
+	var b strings.Builder
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == '\\' && i+1 < len(content):
+			i++
+			b.WriteByte(content[i])
+		case strings.HasPrefix(content[i:], "\r\n"):
+			i++
+		default:
+			b.WriteByte(content[i])
+		}
+	}
+	return b.String()
+}
+
+// isCFWS reports whether s holds nothing but comments and white space.
+func (v *EmailValidator) isCFWS(s string) bool {
+	if checkChars(s, v.allowUTF8, true) != nil {
+		return false
+	}
+	p := &addrSpecParser{s: s}
+	return p.cfws(ErrInvalidMailbox) == nil && p.pos == len(s)
+}
+
+/*
+ * nextDelim returns the index of the first byte of delims in s at or
+ * after i that is outside quoted strings, comments, domain literals and
+ * angle-addrs, or len(s) if there is none.
+ */
+func nextDelim(s string, i int, delims string) (int, *ValidationError) {
+	for i < len(s) {
+		c := s[i]
+		if strings.IndexByte(delims, c) >= 0 {
+			return i, nil
+		}
+		if strings.IndexByte(`"([<`, c) >= 0 {
+			end := skipNested(s, i)
+			if end < 0 {
+				return 0, &ValidationError{Err: ErrInvalidMailbox, Segment: s[i:], Offset: i}
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return len(s), nil
+}
+
+/*
+ * skipNested returns the index just past the quoted string, comment,
+ * domain literal or angle-addr starting at s[i], or -1 if it doesn't
+ * end.
+ */
+func skipNested(s string, i int) int {
+	switch s[i] {
+	case '"':
+		for j := i + 1; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '"':
+				return j + 1
+			}
+		}
+	case '(':
+		depth := 0
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '(':
+				depth++
+			case ')':
+				if depth--; depth == 0 {
+					return j + 1
+				}
+			}
+		}
+	case '[':
+		if j := strings.IndexByte(s[i:], ']'); j >= 0 {
+			return i + j + 1
+		}
+	case '<':
+		for j := i + 1; j < len(s); {
+			switch s[j] {
+			case '>':
+				return j + 1
+			case '"', '(', '[':
+				if j = skipNested(s, j); j < 0 {
+					return -1
+				}
+				continue
+			}
+			j++
+		}
+	}
+	return -1
+}
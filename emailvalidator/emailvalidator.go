@@ -19,147 +19,595 @@
 package emailvalidator
 
 import (
-	//"fmt"
+	"errors"
+	"fmt"
+	"net"
 	"regexp"
-	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dsparling/go-commons-validator/domainvalidator"
+	"github.com/dsparling/go-commons-validator/inetaddressvalidator"
 )
 
 const (
-	// TODO: error parsing regexp: invalid character class range: `\p{Cntrl}`
-	// \p{Cntrl} 	A control character: [\x00-\x1F\x7F]
-	//SPECIAL_CHARS = "\\p{Cntrl}\\(\\)<>@,;:'\\\\\\\"\\.\\[\\]"
-	//SPECIAL_CHARS = "\\(\\)<>@,;:'\\\\\\\"\\.\\[\\]"
-	SPECIAL_CHARS = "\x00-\x1F\x7F\\(\\)<>@,;:'\\\\\\\"\\.\\[\\]"
+	// Java's \p{Cntrl} is [\x00-\x1F\x7F], which RE2 doesn't support;
+	// \p{Cc} also covers the C1 controls [\x{80}-\x{9F}]
+	CONTROL_CHARS = "\\p{Cc}"
+	SPECIAL_CHARS = CONTROL_CHARS + "\\(\\)<>@,;:'\\\\\\\"\\.\\[\\]"
 	VALID_CHARS   = "[^\\s" + SPECIAL_CHARS + "]"
 	QUOTED_USER   = "(\"[^\"]*\")"
 	WORD          = "((" + VALID_CHARS + "|')+|" + QUOTED_USER + ")"
 
 	// \p{ASCII} 	All ASCII:[\x00-\x7F]
-	LEGAL_ASCII_REGEX = `^\\p{ASCII}+$`
+	//
+	// Deprecated: LEGAL_ASCII_REGEX is no longer used. Validate checks
+	// characters with a byte scan, see checkChars.
+	LEGAL_ASCII_REGEX = `^[\x00-\x7F]+$`
 	EMAIL_REGEX       = `^(.+)@(.+?)$`
 	USER_REGEX        = "^" + WORD + "(\\." + WORD + ")*$"
 	IP_DOMAIN_REGEX   = "^\\[(.*)\\]$"
 
-	IPV4_REGEX = "^(\\d{1,3})\\.(\\d{1,3})\\.(\\d{1,3})\\.(\\d{1,3})$"
+	IPV6_TAG                   = "IPv6:"
+	IPV6_MAX_COMPRESSED_GROUPS = 6
+)
+
+// RFC 5321 section 4.5.3.1 size limits, in octets
+const (
+	MAX_LOCAL_PART_LENGTH = 64
+	MAX_DOMAIN_LENGTH     = 255
+	MAX_LABEL_LENGTH      = domainvalidator.MAX_LABEL_LENGTH
+	// A path of at most 256 octets, less the angle brackets
+	MAX_ADDRESS_LENGTH = 254
 )
 
+var (
+	ErrEmpty            = errors.New("emailvalidator: empty address")
+	ErrNonASCII         = errors.New("emailvalidator: non-ASCII character")
+	ErrControlChar      = errors.New("emailvalidator: control character")
+	ErrInvalidUTF8      = errors.New("emailvalidator: invalid UTF-8")
+	ErrMissingAt        = errors.New("emailvalidator: missing @")
+	ErrTrailingDot      = errors.New("emailvalidator: trailing dot")
+	ErrInvalidLocalPart = errors.New("emailvalidator: invalid local part")
+	ErrLocalPartTooLong = errors.New("emailvalidator: local part too long")
+	ErrAddressTooLong   = errors.New("emailvalidator: address too long")
+
+	// A domain such as [192.168.1.1] or [IPv6:2001:db8::1] that is not a
+	// valid address literal
+	ErrInvalidAddressLiteral = errors.New("emailvalidator: invalid address literal")
+
+	// Domain errors are shared with domainvalidator
+	ErrInvalidDomain = domainvalidator.ErrInvalidDomain
+	ErrUnknownTLD    = domainvalidator.ErrUnknownTLD
+	ErrLabelTooLong  = domainvalidator.ErrLabelTooLong
+	ErrDomainTooLong = domainvalidator.ErrDomainTooLong
+)
+
+/**
+ * ValidationError reports why an email address failed validation.
+ * Err is one of the Err* values of this package, Segment is the
+ * offending part of the input and Offset is its byte offset within
+ * the string passed to Validate.
+ */
+type ValidationError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Compiled once; *regexp.Regexp is safe for concurrent use
+var (
+	userRegex     = regexp.MustCompile(USER_REGEX)
+	ipDomainRegex = regexp.MustCompile(IP_DOMAIN_REGEX)
+)
+
+/**
+ * Address is a valid e-mail address split into its parts, as returned
+ * by Parse. LocalPart and Domain are as written, so a quoted local part
+ * keeps its quotes and an IP literal domain its brackets; surrounding
+ * whitespace, and comments in strict mode, are not included. For an IP
+ * literal, IP is the address it holds.
+ */
+type Address struct {
+	LocalPart   string
+	Domain      string
+	IsIPLiteral bool
+	IP          net.IP
+	Quoted      bool
+}
+
+/**
+ * EmailValidator performs email validation. The zero value, like the
+ * validator returned by New with no options, rejects local addresses
+ * and bare TLD domains.
+ */
+type EmailValidator struct {
+	allowLocal      bool
+	allowTld        bool
+	allowUTF8       bool
+	strict          bool
+	maxLocalPart    int
+	maxDomain       int
+	maxLabel        int
+	maxAddress      int
+	domainValidator *domainvalidator.DomainValidator
+}
+
+/**
+ * Option configures an EmailValidator created with New.
+ */
+type Option func(*EmailValidator)
+
+/**
+ * AllowLocal sets whether local addresses such as user@localhost and
+ * user@localhost.localdomain are considered valid.
+ * @param allowLocal Should local addresses be considered valid?
+ */
+func AllowLocal(allowLocal bool) Option {
+	return func(v *EmailValidator) {
+		v.allowLocal = allowLocal
+	}
+}
+
+/**
+ * AllowTld sets whether addresses whose domain is a bare top-level
+ * domain, such as root@com, are considered valid.
+ * @param allowTld Should TLDs be allowed?
+ */
+func AllowTld(allowTld bool) Option {
+	return func(v *EmailValidator) {
+		v.allowTld = allowTld
+	}
+}
+
+/**
+ * AllowUTF8 sets whether internationalized addresses (RFC 6531 SMTPUTF8)
+ * such as 用户@例子.公司 are considered valid. The local part may then
+ * contain printable non-ASCII characters, and the domain may be an
+ * internationalized domain name in Unicode or as A-labels. Its TLD must
+ * still be in the domainvalidator tables (see domainvalidator/tlds.go)
+ * or in the overrides of WithDomainValidator, so 用户@例子.广告 is
+ * rejected with ErrUnknownTLD: 广告 is not a TLD in the tables.
+ * @param allowUTF8 Should UTF-8 addresses be considered valid?
+ */
+func AllowUTF8(allowUTF8 bool) Option {
+	return func(v *EmailValidator) {
+		v.allowUTF8 = allowUTF8
+	}
+}
+
+/**
+ * Strict sets whether addresses are checked with an RFC 5322 addr-spec
+ * parser instead of the pragmatic USER_REGEX check. In strict mode
+ * quoted pairs such as "a\\"b"@x.com, comments and folding white space
+ * are understood, and only syntactically legal addresses are accepted.
+ * The domain must still be a valid domain name or IP address literal.
+ * @param strict Should addresses be parsed strictly?
+ */
+func Strict(strict bool) Option {
+	return func(v *EmailValidator) {
+		v.strict = strict
+	}
+}
+
+/**
+ * MaxLocalPartLength sets the longest local part, in octets, that is
+ * considered valid. The default is MAX_LOCAL_PART_LENGTH; zero or less
+ * removes the limit.
+ * @param n the maximum length of the local part
+ */
+func MaxLocalPartLength(n int) Option {
+	return func(v *EmailValidator) {
+		v.maxLocalPart = setLimit(n)
+	}
+}
+
+/**
+ * MaxDomainLength sets the longest domain, in octets, that is
+ * considered valid. The default is MAX_DOMAIN_LENGTH; zero or less
+ * removes the limit. Domain names can't be longer than
+ * domainvalidator.MAX_DOMAIN_LENGTH, so only lower limits change the
+ * result for them.
+ * @param n the maximum length of the domain
+ */
+func MaxDomainLength(n int) Option {
+	return func(v *EmailValidator) {
+		v.maxDomain = setLimit(n)
+	}
+}
+
+/**
+ * MaxLabelLength sets the longest domain label, in octets, that is
+ * considered valid. The default is MAX_LABEL_LENGTH; zero or less
+ * removes the limit. Domain names can't have labels longer than
+ * MAX_LABEL_LENGTH, so only lower limits change the result.
+ * @param n the maximum length of a domain label
+ */
+func MaxLabelLength(n int) Option {
+	return func(v *EmailValidator) {
+		v.maxLabel = setLimit(n)
+	}
+}
+
+/**
+ * MaxAddressLength sets the longest address, in octets, that is
+ * considered valid. The default is MAX_ADDRESS_LENGTH; zero or less
+ * removes the limit.
+ * @param n the maximum length of the address
+ */
+func MaxAddressLength(n int) Option {
+	return func(v *EmailValidator) {
+		v.maxAddress = setLimit(n)
+	}
+}
+
+/**
+ * WithDomainValidator sets the DomainValidator that checks symbolic
+ * domains, so that its TLD overrides apply to email addresses too. It
+ * is shared, not copied: later overrides apply as well. Local domains
+ * are then valid if domainValidator allows them, whatever AllowLocal
+ * says.
+ * @param domainValidator the validator to use, or nil for one created
+ * by New
+ */
+func WithDomainValidator(domainValidator *domainvalidator.DomainValidator) Option {
+	return func(v *EmailValidator) {
+		v.domainValidator = domainValidator
+	}
+}
+
+/**
+ * New returns an EmailValidator configured with the given options.
+ * @param opts the options to apply
+ * @return the configured validator
+ */
+func New(opts ...Option) *EmailValidator {
+	v := &EmailValidator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.domainValidator == nil {
+		v.domainValidator = domainvalidator.New(domainvalidator.AllowLocal(v.allowLocal))
+	}
+	return v
+}
+
+var defaultValidator = New()
+
+// defaultDomainValidator serves validators not created with New
+var defaultDomainValidator = domainvalidator.New()
+
+// domains returns the domain validator of v.
+func (v *EmailValidator) domains() *domainvalidator.DomainValidator {
+	if v.domainValidator == nil {
+		return defaultDomainValidator
+	}
+	return v.domainValidator
+}
+
+// setLimit returns the value a Max*Length option stores for n: n
+// itself, or -1 for no limit, so that zero keeps the default.
+func setLimit(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
+
+// limit returns the limit stored by setLimit, def if none was stored,
+// or zero if there is no limit.
+func limit(n, def int) int {
+	switch {
+	case n == 0:
+		return def
+	case n < 0:
+		return 0
+	}
+	return n
+}
+
+/**
+ * Checks if a field has a valid e-mail address, using the default
+ * validator which allows neither local addresses nor bare TLDs.
+ * @param emailAddress The value validation is being performed on.
+ * @return true if the email address is valid.
+ */
 func IsValid(emailAddress string) bool {
+	return defaultValidator.IsValid(emailAddress)
+}
+
+/**
+ * Checks if a field has a valid e-mail address.
+ * @param emailAddress The value validation is being performed on.
+ * @return true if the email address is valid.
+ */
+func (v *EmailValidator) IsValid(emailAddress string) bool {
+	return v.Validate(emailAddress) == nil
+}
+
+/**
+ * Validates an e-mail address using the default validator.
+ * @param emailAddress The value validation is being performed on.
+ * @return nil if the email address is valid, otherwise a *ValidationError.
+ */
+func Validate(emailAddress string) error {
+	return defaultValidator.Validate(emailAddress)
+}
+
+/**
+ * Validates an e-mail address, reporting the first problem found.
+ * Leading and trailing whitespace is ignored, but offsets in the
+ * returned error are relative to emailAddress as given.
+ * @param emailAddress The value validation is being performed on.
+ * @return nil if the email address is valid, otherwise a *ValidationError.
+ */
+func (v *EmailValidator) Validate(emailAddress string) error {
+	if _, err := v.parse(emailAddress); err != nil {
+		return err
+	}
+	return nil
+}
+
+/**
+ * Parses an e-mail address using the default validator.
+ * @param emailAddress The value validation is being performed on.
+ * @return the parts of the address, and nil if the email address is
+ * valid, otherwise a *ValidationError.
+ */
+func Parse(emailAddress string) (Address, error) {
+	return defaultValidator.Parse(emailAddress)
+}
+
+/**
+ * Parses an e-mail address into its parts. It accepts exactly what
+ * Validate accepts, splitting the address the same way.
+ * @param emailAddress The value validation is being performed on.
+ * @return the parts of the address, and nil if the email address is
+ * valid, otherwise a *ValidationError.
+ */
+func (v *EmailValidator) Parse(emailAddress string) (Address, error) {
+	spec, err := v.parse(emailAddress)
+	if err != nil {
+		return Address{}, err
+	}
+	return newAddress(spec), nil
+}
+
+// parse validates emailAddress and locates its parts; both Validate
+// and Parse are built on it.
+func (v *EmailValidator) parse(emailAddress string) (addrSpec, *ValidationError) {
+	if v.strict {
+		return v.parseStrict(emailAddress)
+	}
+
+	lead := len(emailAddress) - len(strings.TrimLeftFunc(emailAddress, unicode.IsSpace))
 	emailAddress = strings.TrimSpace(emailAddress)
 
 	if emailAddress == "" {
-		return false
+		return addrSpec{}, &ValidationError{Err: ErrEmpty}
 	}
 
-	r, _ := regexp.Compile(LEGAL_ASCII_REGEX)
-	match := r.MatchString(emailAddress)
-	if match {
-		return false
+	if err := checkChars(emailAddress, v.allowUTF8, false); err != nil {
+		err.Offset += lead
+		return addrSpec{}, err
 	}
 
 	// Check the whole email address structure
-	r2, _ := regexp.Compile(EMAIL_REGEX)
-	match2 := r2.MatchString(emailAddress)
-	if !match2 {
-		return false
+	user, domain, ok := splitAddress(emailAddress)
+	if !ok {
+		return addrSpec{}, structureError(emailAddress, lead)
 	}
 
 	if strings.HasSuffix(emailAddress, ".") {
-		return false
+		return addrSpec{}, &ValidationError{Err: ErrTrailingDot, Segment: ".", Offset: lead + len(emailAddress) - 1}
 	}
 
-	result := r2.FindStringSubmatch(emailAddress)
-	if len(result) < 3 {
-		return false
+	if err := v.checkLengths(user, lead, domain, lead+len(user)+1); err != nil {
+		return addrSpec{}, err
 	}
-	user := result[1]
-	domain := result[2]
 
-	if !isValidUser(user) {
-		return false
+	if !isValidUser(user) || v.allowUTF8 && !isPrintable(user) {
+		return addrSpec{}, &ValidationError{Err: ErrInvalidLocalPart, Segment: user, Offset: lead}
 	}
 
-	if !isValidDomain(domain) {
-		return false
+	if err := v.validateDomain(domain); err != nil {
+		err.Offset += lead + len(user) + 1
+		return addrSpec{}, err
 	}
 
-	return true
+	return addrSpec{
+		localPart:    user,
+		localOffset:  lead,
+		domain:       domain,
+		domainOffset: lead + len(user) + 1,
+		// QUOTED_USER can't contain quotes, so this is a single quoted word
+		quoted:  len(user) >= 2 && user[0] == '"' && strings.IndexByte(user[1:], '"') == len(user)-2,
+		literal: domain[0] == '[' && domain[len(domain)-1] == ']',
+	}, nil
 }
 
-func isValidUser(user string) bool {
-	r, _ := regexp.Compile(USER_REGEX)
-	return r.MatchString(user)
+/*
+ * checkChars rejects control characters (C0, DEL and C1), invalid UTF-8
+ * and, unless allowUTF8 is set, any other non-ASCII character. If fws
+ * is set, the tab, CR and LF of folding white space are let through.
+ * Offsets in the returned error are relative to the start of s.
+ */
+func checkChars(s string, allowUTF8, fws bool) *ValidationError {
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if (c < ' ' || c == '\x7F') && !(fws && (c == '\t' || c == '\r' || c == '\n')) {
+				return &ValidationError{Err: ErrControlChar, Segment: s[i : i+1], Offset: i}
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			return &ValidationError{Err: ErrInvalidUTF8, Segment: s[i : i+1], Offset: i}
+		case unicode.Is(unicode.Cc, r):
+			return &ValidationError{Err: ErrControlChar, Segment: s[i : i+size], Offset: i}
+		case !allowUTF8:
+			return &ValidationError{Err: ErrNonASCII, Segment: s[i : i+size], Offset: i}
+		}
+		i += size
+	}
+	return nil
 }
 
-func isValidDomain(domain string) bool {
-	// see if domain is an IP address in brackets
-	//	Matcher ipDomainMatcher = IP_DOMAIN_PATTERN.matcher(domain);
-	r, _ := regexp.Compile(IP_DOMAIN_REGEX)
+/*
+ * splitAddress splits emailAddress into user and domain as EMAIL_REGEX
+ * does, without the allocations of FindStringSubmatch: at the last @
+ * that leaves both parts non-empty. As with the regex's dots, neither
+ * part may contain a newline.
+ */
+func splitAddress(emailAddress string) (user, domain string, ok bool) {
+	if emailAddress == "" || strings.IndexByte(emailAddress, '\n') >= 0 {
+		return "", "", false
+	}
+	at := strings.LastIndexByte(emailAddress[:len(emailAddress)-1], '@')
+	if at < 1 {
+		return "", "", false
+	}
+	return emailAddress[:at], emailAddress[at+1:], true
+}
 
-	match := r.MatchString(domain)
-	if match {
-		// Domain is IP address in brackets
-		//fmt.Println("Domain is IP address in brackets")
-		//fmt.Println(domain)
-		groups := r.FindStringSubmatch(domain)
-		if len(groups) < 2 {
-			return false
-		}
-		inet4Address := groups[1]
-		//fmt.Println("inet4Address")
-		//fmt.Println(inet4Address)
-
-		r2, _ := regexp.Compile(IPV4_REGEX)
-		match2 := r2.MatchString(inet4Address)
-		if match2 {
-			// Check if it's an Inet4 Address
-			groups2 := r2.FindStringSubmatch(inet4Address)
-			if len(groups2) < 5 {
-				return false
+// structureError explains why emailAddress doesn't match EMAIL_REGEX.
+func structureError(emailAddress string, lead int) *ValidationError {
+	at := strings.LastIndex(emailAddress, "@")
+	switch {
+	case at < 0:
+		return &ValidationError{Err: ErrMissingAt, Segment: emailAddress, Offset: lead}
+	case at == 0:
+		return &ValidationError{Err: ErrInvalidLocalPart, Offset: lead}
+	case at == len(emailAddress)-1 && strings.LastIndex(emailAddress[:at], "@") < 0:
+		return &ValidationError{Err: ErrInvalidDomain, Offset: lead + at + 1}
+	case strings.Contains(emailAddress[:at], "\n"):
+		// newlines never match EMAIL_REGEX
+		return &ValidationError{Err: ErrInvalidLocalPart, Segment: emailAddress[:at], Offset: lead}
+	}
+	return &ValidationError{Err: ErrInvalidDomain, Segment: emailAddress[at+1:], Offset: lead + at + 1}
+}
+
+// checkLengths enforces the configured size limits on the local part
+// and domain, found at the given offsets of the input.
+func (v *EmailValidator) checkLengths(user string, userOffset int, domain string, domainOffset int) *ValidationError {
+	if maxLocalPart := limit(v.maxLocalPart, MAX_LOCAL_PART_LENGTH); maxLocalPart > 0 && len(user) > maxLocalPart {
+		return &ValidationError{Err: ErrLocalPartTooLong, Segment: user, Offset: userOffset}
+	}
+	offset := domainOffset
+	if maxDomain := limit(v.maxDomain, MAX_DOMAIN_LENGTH); maxDomain > 0 && len(domain) > maxDomain {
+		return &ValidationError{Err: ErrDomainTooLong, Segment: domain, Offset: offset}
+	}
+	if maxLabel := limit(v.maxLabel, MAX_LABEL_LENGTH); maxLabel > 0 && domain[0] != '[' {
+		for rest := domain; ; {
+			end := strings.IndexByte(rest, '.')
+			if end < 0 {
+				end = len(rest)
 			}
-			// TODO: IP6
-			//fmt.Println("IP4")
-
-			// verify that address subgroups are legal
-			for i := 1; i <= len(groups2)-1; i++ {
-				ipSegment := groups2[i]
-				//fmt.Println(ipSegment)
-
-				if ipSegment == "" || len(ipSegment) <= 0 {
-					return false
-				}
-
-				// Make sure it's a number
-				iIpSegment, err := strconv.Atoi(ipSegment)
-				if err != nil {
-					return false
-				}
-
-				if iIpSegment > 255 {
-					return false
-				}
+			if end > maxLabel {
+				return &ValidationError{Err: ErrLabelTooLong, Segment: rest[:end], Offset: offset}
 			}
-			return true
-		} else {
-			// More than four segments, or a segment has more than three digits
-			return false
+			if end == len(rest) {
+				break
+			}
+			rest = rest[end+1:]
+			offset += end + 1
 		}
-	} else {
-		// TODO: Domain is symbolic name
-		//fmt.Println("Domain is symbolic name")
-		//fmt.Println(domain)
-	}
-	// TODO
-	//	if (ipDomainMatcher.matches()) {
-	//	InetAddressValidator inetAddressValidator =
-	//	InetAddressValidator.getInstance();
-	//	return inetAddressValidator.isValid(ipDomainMatcher.group(1));
-	//	} else {
-	//	// Domain is symbolic name
-	//	DomainValidator domainValidator =
-	//	DomainValidator.getInstance(allowLocal);
-	//	return domainValidator.isValid(domain);
-	//	}
+	}
+	if maxAddress := limit(v.maxAddress, MAX_ADDRESS_LENGTH); maxAddress > 0 && len(user)+1+len(domain) > maxAddress {
+		return &ValidationError{Err: ErrAddressTooLong, Segment: user + "@" + domain, Offset: userOffset}
+	}
+	return nil
+}
 
+func isValidUser(user string) bool {
+	return userRegex.MatchString(user)
+}
+
+// isPrintable reports whether the non-ASCII characters of s, which
+// VALID_CHARS lets through, are all printable and not spaces.
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if c >= utf8.RuneSelf && (!unicode.IsGraphic(c) || unicode.IsSpace(c)) {
+			return false
+		}
+	}
 	return true
 }
+
+// validateDomain validates the part after the @. Offsets in the
+// returned error are relative to the start of domain.
+func (v *EmailValidator) validateDomain(domain string) *ValidationError {
+	if v.isValidDomain(domain) {
+		return nil
+	}
+	if ipDomainRegex.MatchString(domain) {
+		return &ValidationError{Err: ErrInvalidAddressLiteral, Segment: domain}
+	}
+	var err *domainvalidator.ValidationError
+	if errors.As(v.domains().Validate(domain), &err) {
+		return &ValidationError{Err: err.Err, Segment: err.Segment, Offset: err.Offset}
+	}
+	return &ValidationError{Err: ErrInvalidDomain, Segment: domain}
+}
+
+func (v *EmailValidator) isValidDomain(domain string) bool {
+	// see if domain is an IP address in brackets
+	if ipDomainRegex.MatchString(domain) {
+		// Domain is IP address in brackets
+		ipAddress := domain[1 : len(domain)-1]
+
+		// RFC 5321 tags IPv6 address literals, e.g. [IPv6:2001:db8::1]
+		if hasIPv6Tag(ipAddress) {
+			return isValidInet6Literal(ipAddress[len(IPV6_TAG):])
+		}
+		return inetaddressvalidator.IsValidInet4Address(ipAddress)
+	}
+
+	// Domain is symbolic name
+	if v.allowTld {
+		return v.domains().IsValid(domain) ||
+			(!strings.HasPrefix(domain, ".") && v.domains().IsValidTld(domain))
+	}
+	return v.domains().IsValid(domain)
+}
+
+// hasIPv6Tag reports whether the content of an address literal starts
+// with the case insensitive IPv6 tag of RFC 5321.
+func hasIPv6Tag(ipAddress string) bool {
+	return len(ipAddress) >= len(IPV6_TAG) && strings.EqualFold(ipAddress[:len(IPV6_TAG)], IPV6_TAG)
+}
+
+/*
+ * Validates an RFC 5321 IPv6 address literal. On top of the RFC 4291
+ * syntax, section 4.1.3 requires "::" to stand for at least two zero
+ * groups, so at most six groups may be written alongside it.
+ */
+func isValidInet6Literal(inet6Address string) bool {
+	if !inetaddressvalidator.IsValidInet6Address(inet6Address) {
+		return false
+	}
+	if !strings.Contains(inet6Address, "::") {
+		return true
+	}
+	groups := 0
+	for _, group := range strings.Split(inet6Address, ":") {
+		if strings.Contains(group, ".") {
+			// IPv4 tail takes two groups
+			groups += 2
+		} else if group != "" {
+			groups++
+		}
+	}
+	return groups <= IPV6_MAX_COMPRESSED_GROUPS
+}
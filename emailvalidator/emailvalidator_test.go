@@ -19,7 +19,14 @@
 package emailvalidator
 
 import (
+	"errors"
+	"net"
+	"regexp"
+	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/dsparling/go-commons-validator/domainvalidator"
 )
 
 /**
@@ -29,7 +36,7 @@ func TestEmail(t *testing.T) {
 	email := "jsmith@apache.org"
 	valid := IsValid(email)
 	if !valid {
-		t.Errorf("expected valid email address:", email)
+		t.Errorf("expected valid email address: %s", email)
 	}
 }
 
@@ -45,7 +52,59 @@ func TestEmailWithNumericAddress(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected valid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
+		}
+	}
+}
+
+/**
+ * Tests the email validation with RFC 5321 IPv6 address literals.
+ */
+func TestEmailWithIPv6Address(t *testing.T) {
+	validEmails := []string{
+		`someone@[IPv6:2001:db8::1]`,
+		`someone@[IPv6:2001:db8:0:0:0:0:0:1]`,
+		`someone@[IPv6:2001:DB8:85A3:0:0:8A2E:370:7334]`,
+		`someone@[IPv6:::1]`,
+		`someone@[IPv6:::]`,
+		`someone@[IPv6:fe80::]`,
+		`someone@[ipv6:2001:db8::1]`,                // the tag is case-insensitive
+		`someone@[IPv6:1:2:3:4:5:6::]`,              // six groups plus "::"
+		`someone@[IPv6:::ffff:192.0.2.1]`,           // IPv4 tail
+		`someone@[IPv6:2001:db8:1:2:3:4:192.0.2.1]`, // six groups and IPv4 tail
+		`someone@[IPv6:1:2:3:4::192.0.2.1]`,         // four groups, "::" and IPv4 tail
+	}
+	for _, email := range validEmails {
+		valid := IsValid(email)
+
+		if !valid {
+			t.Errorf("expected valid email address: %s", email)
+		}
+	}
+
+	invalidEmails := []string{
+		`someone@[2001:db8::1]`,               // missing IPv6 tag
+		`someone@[IPv6:]`,                     // no address
+		`someone@[IPv6:2001:db8::1::2]`,       // doubled compression
+		`someone@[IPv6:2001:db8:::1]`,         // tripled colon
+		`someone@[IPv6:1:2:3:4:5:6:7::]`,      // compression too long
+		`someone@[IPv6:1:2:3:4:5:6::7]`,       // compression too long
+		`someone@[IPv6:1:2:3:4:5::192.0.2.1]`, // compression too long with IPv4 tail
+		`someone@[IPv6:1:2:3:4:5:6:7]`,        // too few groups
+		`someone@[IPv6:1:2:3:4:5:6:7:8:9]`,    // too many groups
+		`someone@[IPv6:1:2:3:4:5:6:7:192.0.2.1]`,
+		`someone@[IPv6:2001:db8::g]`,         // not hex
+		`someone@[IPv6:2001:db8::12345]`,     // group too long
+		`someone@[IPv6::2001:db8::1]`,        // leading single colon
+		`someone@[IPv6:2001:db8:1:2:3:4:5:]`, // trailing single colon
+		`someone@[IPv6:::ffff:256.0.0.1]`,    // bad IPv4 tail
+		`someone@[IPv6:::ffff:192.0.2]`,      // short IPv4 tail
+	}
+	for _, email := range invalidEmails {
+		valid := IsValid(email)
+
+		if valid {
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -59,26 +118,27 @@ func TestEmailExtension(t *testing.T) {
 		`jsmith@apache.com`,
 		`jsmith@apache.net`,
 		`jsmith@apache.info`,
+		`someone@yahoo.museum`,
 	}
 	for _, email := range validEmails {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
 	invalidEmails := []string{
 		`jsmith@apache.`,
-		//`jsmith@apache.c`,
-		//`someone@yahoo.museum`,
-		//`someone@yahoo.mu-seum`,
+		`jsmith@apache.c`,
+		`someone@yahoo.mu-seum`,
+		`someone@yahoo.rog`,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -95,20 +155,21 @@ func TestEmailWithDash(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
 	invalidEmails := []string{
-	//`andy-noble@data-workshop.-com`,
-	//`andy-noble@data-workshop.c-om`,
-	//`andy-noble@data-workshop.co-m`,
+		`andy-noble@data-workshop.-com`,
+		`andy-noble@data-workshop.c-om`,
+		`andy-noble@data-workshop.co-m`,
+		`a@-bad-.com`,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -121,7 +182,7 @@ func TestEmailWithDotEnd(t *testing.T) {
 	email := "andy.noble@data-workshop.com."
 	valid := IsValid(email)
 	if valid {
-		t.Errorf("expected invalid email address:", email)
+		t.Errorf("expected invalid email address: %s", email)
 	}
 }
 
@@ -140,25 +201,25 @@ func TestEmailWithBogusCharacter(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
 	invalidEmails := []string{
-	//`andy.noble@\u008fdata-workshop.com`,
-	// The ' character is not valid in the domain name.
-	//`andy@o'reilly.data-workshop.com`,
-	// The + character is not valid in the domain name.
-	//`foo+bar@example+3.com`,
-	// Domains with only special characters aren't allowed (VALIDATOR-286)
-	//`test@%*.com`,
-	//`test@^&#.com`,
+		"andy.noble@\u008fdata-workshop.com",
+		// The ' character is not valid in the domain name.
+		`andy@o'reilly.data-workshop.com`,
+		// The + character is not valid in the domain name.
+		`foo+bar@example+3.com`,
+		// Domains with only special characters aren't allowed (VALIDATOR-286)
+		`test@%*.com`,
+		`test@^&#.com`,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -168,15 +229,15 @@ func TestEmailWithBogusCharacter(t *testing.T) {
  */
 func TestEmailWithCommas(t *testing.T) {
 	invalidEmails := []string{
-	//`joeblow@apa,che.org`,
-	//`joeblow@apache.o,rg`,
-	//`joeblow@apache,org`,
+		`joeblow@apa,che.org`,
+		`joeblow@apache.o,rg`,
+		`joeblow@apache,org`,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -193,21 +254,21 @@ func TestEmailWithSpaces(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected valid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
 	invalidEmails := []string{
 		`joeblow @apache.org`, // TODO - this should be valid?
-		//`joeblow@ apache.org`,
+		`joeblow@ apache.org`,
 		`joe blow@apache.org `,
-		//`joeblow@apa che.org `,
+		`joeblow@apa che.org `,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -216,54 +277,229 @@ func TestEmailWithSpaces(t *testing.T) {
  * Tests the email validation with ascii control characters.
  * (i.e. Ascii chars 0 - 31 and 127)
  */
-//func TestEmailWithSpaces(t *testing.T) {
-//	invalidEmails := []string{
-//	}
-//	for _, email := range invalidEmails {
-//		valid := IsValid(email)
-//
-//		if valid {
-//			t.Errorf("expected invalid email address:", email)
-//		}
-//	}
-//}
-
-//public void testEmailWithControlChars() {
-//	for (char c = 0; c < 32; c++) {
-//		assertFalse("Test control char " + ((int)c), validator.isValid("foo" + c + "bar@domain.com"));
-//	}
-//	assertFalse("Test control char 127", validator.isValid("foo" + ((char)127) + "bar@domain.com"));
-//}
+func TestEmailWithControlChars(t *testing.T) {
+	for c := rune(0); c < 32; c++ {
+		if IsValid("foo" + string(c) + "bar@domain.com") {
+			t.Errorf("expected invalid email address with control char %d", c)
+		}
+	}
+	if IsValid("foo" + string(rune(127)) + "bar@domain.com") {
+		t.Errorf("expected invalid email address with control char %d", 127)
+	}
+}
 
 /**
  * Test that @localhost and @localhost.localdomain
  * addresses are declared as valid when requested.
  */
-//public void testEmailLocalhost() {
-//	// Check the default is not to allow
-//	EmailValidator noLocal = EmailValidator.getInstance(false);
-//	EmailValidator allowLocal = EmailValidator.getInstance(true);
-//	assertEquals(validator, noLocal);
-//
-//	// Depends on the validator
-//	assertTrue(
-//		"@localhost.localdomain should be accepted but wasn't",
-//		allowLocal.isValid("joe@localhost.localdomain")
-//	);
-//	assertTrue(
-//		"@localhost should be accepted but wasn't",
-//		allowLocal.isValid("joe@localhost")
-//	);
-//
-//	assertFalse(
-//		"@localhost.localdomain should be accepted but wasn't",
-//		noLocal.isValid("joe@localhost.localdomain")
-//	);
-//	assertFalse(
-//		"@localhost should be accepted but wasn't",
-//		noLocal.isValid("joe@localhost")
-//	);
-//}
+func TestEmailLocalhost(t *testing.T) {
+	// Check the default is not to allow
+	noLocal := New(AllowLocal(false))
+	allowLocal := New(AllowLocal(true))
+
+	// Depends on the validator
+	localEmails := []string{
+		`joe@localhost.localdomain`,
+		`joe@localhost`,
+		`joe@localdomain`,
+	}
+	for _, email := range localEmails {
+		if !allowLocal.IsValid(email) {
+			t.Errorf("expected local email address to be accepted: %s", email)
+		}
+		if noLocal.IsValid(email) {
+			t.Errorf("expected local email address to be rejected: %s", email)
+		}
+		if IsValid(email) {
+			t.Errorf("expected local email address to be rejected by default: %s", email)
+		}
+	}
+
+	invalidEmails := []string{
+		`joe@localhost.`,
+		`joe@.localhost`,
+		`joe@local host`,
+		`joe@localhost.rog`,
+	}
+	for _, email := range invalidEmails {
+		if allowLocal.IsValid(email) {
+			t.Errorf("expected invalid email address: %s", email)
+		}
+	}
+}
+
+/**
+ * Test that addresses with a bare top-level domain
+ * are declared as valid when requested.
+ */
+func TestEmailAtTld(t *testing.T) {
+	noTld := New()
+	allowTld := New(AllowTld(true))
+
+	tldEmails := []string{
+		`root@com`,
+		`root@COM`,
+		`test@org`,
+	}
+	for _, email := range tldEmails {
+		if !allowTld.IsValid(email) {
+			t.Errorf("expected TLD email address to be accepted: %s", email)
+		}
+		if noTld.IsValid(email) {
+			t.Errorf("expected TLD email address to be rejected: %s", email)
+		}
+	}
+
+	invalidEmails := []string{
+		`root@.com`,
+		`root@rog`,
+		`root@localhost`,
+	}
+	for _, email := range invalidEmails {
+		if allowTld.IsValid(email) {
+			t.Errorf("expected invalid email address: %s", email)
+		}
+	}
+}
+
+/**
+ * Test that a shared DomainValidator's TLD overrides apply
+ * to addresses, including overrides made after New.
+ */
+func TestEmailWithDomainValidator(t *testing.T) {
+	dv := domainvalidator.New()
+	if err := dv.UpdateTLDOverride(domainvalidator.GENERIC_PLUS, []string{"corp"}); err != nil {
+		t.Fatal(err)
+	}
+	validator := New(WithDomainValidator(dv))
+
+	email := `jsmith@apache.corp`
+	if !validator.IsValid(email) {
+		t.Errorf("expected valid email address with shared DomainValidator: %s", email)
+	}
+	if IsValid(email) {
+		t.Errorf("expected invalid email address by default: %s", email)
+	}
+
+	email = `jsmith@apache.lan`
+	if validator.IsValid(email) {
+		t.Errorf("expected invalid email address before override: %s", email)
+	}
+	if err := dv.UpdateTLDOverride(domainvalidator.GENERIC_PLUS, []string{"corp", "lan"}); err != nil {
+		t.Fatal(err)
+	}
+	if !validator.IsValid(email) {
+		t.Errorf("expected valid email address after override: %s", email)
+	}
+
+	// The shared validator decides on local domains, not AllowLocal
+	email = `joe@localhost`
+	if New(WithDomainValidator(dv), AllowLocal(true)).IsValid(email) {
+		t.Errorf("expected local email address to be rejected: %s", email)
+	}
+	local := domainvalidator.New(domainvalidator.AllowLocal(true))
+	if !New(WithDomainValidator(local)).IsValid(email) {
+		t.Errorf("expected local email address to be accepted: %s", email)
+	}
+}
+
+/**
+ * Test that the zero value of EmailValidator validates
+ * like the validator returned by New with no options.
+ */
+func TestZeroValue(t *testing.T) {
+	var zero EmailValidator
+
+	emails := []string{
+		`jsmith@apache.org`,
+		` jsmith@apache.org `,
+		`joe@[192.168.1.1]`,
+		`joe@[IPv6:2001:db8::1]`,
+		`joe@localhost`,
+		`root@com`,
+		`jsmith@apache.rog`,
+		`用户@例子.公司`,
+		strings.Repeat(`a`, 65) + `@apache.org`,
+		`jsmith@` + strings.Repeat(`b`, 64) + `.org`,
+		``,
+	}
+	for _, email := range emails {
+		if got, want := zero.IsValid(email), IsValid(email); got != want {
+			t.Errorf("zero value IsValid(%q) = %v, expected %v", email, got, want)
+		}
+		got, want := zero.Validate(email), Validate(email)
+		if (got == nil) != (want == nil) || got != nil && got.Error() != want.Error() {
+			t.Errorf("zero value Validate(%q) = %v, expected %v", email, got, want)
+		}
+	}
+
+	if got, err := zero.Normalize(`JSmith@Apache.ORG`); got != `JSmith@apache.org` || err != nil {
+		t.Errorf("zero value Normalize = %q, %v, expected %q", got, err, `JSmith@apache.org`)
+	}
+}
+
+/**
+ * Test that internationalized (SMTPUTF8) addresses are
+ * declared as valid only when requested.
+ */
+func TestEmailUTF8(t *testing.T) {
+	allowUTF8 := New(AllowUTF8(true))
+
+	utf8Emails := []string{
+		`用户@例子.公司`,
+		`用户@xn--fsqu00a.xn--55qx5d`,
+		`δοκιμή@παράδειγμα.ελ`,
+		`аджай@экспорт.рф`,
+		`Pelé@example.com`,
+		`χρήστης.όνομα@bücher.ch`,
+		`"José Díaz"@example.org`,
+	}
+	for _, email := range utf8Emails {
+		if err := allowUTF8.Validate(email); err != nil {
+			t.Errorf("expected UTF-8 email address to be accepted: %s: %v", email, err)
+		}
+		if IsValid(email) {
+			t.Errorf("expected UTF-8 email address to be rejected: %s", email)
+		}
+	}
+
+	invalidEmails := []string{
+		`用户@例子.广告`,               // 广告 is not in the TLD tables
+		`用户@例子`,                  // no TLD
+		"用户\u3000名@例子.公司",        // ideographic space
+		"用户\u200b@例子.公司",         // zero width space isn't printable
+		"user\u0085@example.com", // C1 control character
+		"andy.noble@\u008fdata-workshop.com",
+		`用户@例_子.公司`,
+		`用户@例子.公司。`, // ideographic full stop at the end
+	}
+	for _, email := range invalidEmails {
+		if allowUTF8.IsValid(email) {
+			t.Errorf("expected invalid email address: %s", email)
+		}
+	}
+
+	if err := allowUTF8.Validate("us\xffer@example.com"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("Validate(%q) = %v, expected %v", "us\xffer@example.com", err, ErrInvalidUTF8)
+	} else if verr := err.(*ValidationError); verr.Segment != "\xff" || verr.Offset != 2 {
+		t.Errorf("Validate(%q) = %q at %d, expected %q at %d", "us\xffer@example.com", verr.Segment, verr.Offset, "\xff", 2)
+	}
+	if err := allowUTF8.Validate(`用户@例子.广告`); !errors.Is(err, ErrUnknownTLD) {
+		t.Errorf("Validate(%q) = %v, expected %v", `用户@例子.广告`, err, ErrUnknownTLD)
+	}
+
+	// the example of the request, once its TLD is added as an override
+	dv := domainvalidator.New()
+	if err := dv.UpdateTLDOverride(domainvalidator.GENERIC_PLUS, []string{`广告`}); err != nil {
+		t.Fatal(err)
+	}
+	withTld := New(AllowUTF8(true), WithDomainValidator(dv))
+	for _, email := range []string{`用户@例子.广告`, `用户@例子.xn--4rr70v`} {
+		if err := withTld.Validate(email); err != nil {
+			t.Errorf("expected UTF-8 email address to be accepted with override: %s: %v", email, err)
+		}
+	}
+}
 
 /**
  * VALIDATOR-296 - A / or a ! is valid in the user part,
@@ -278,21 +514,21 @@ func TestEmailWithSlashes(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected valid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
 	invalidEmails := []string{
-	// / not valid in domain
-	//`joe@ap/ache.org`,
-	// ! not valid in domain
-	//`joe@apac!he.org`,
+		// / not valid in domain
+		`joe@ap/ache.org`,
+		// ! not valid in domain
+		`joe@apac!he.org`,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -354,7 +590,7 @@ func TestEmailUserName(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected valid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
@@ -374,7 +610,7 @@ func TestEmailUserName(t *testing.T) {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
 		}
 	}
 }
@@ -484,18 +720,566 @@ func TestValidator293(t *testing.T) {
 		valid := IsValid(email)
 
 		if !valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected valid email address: %s", email)
 		}
 	}
 
 	invalidEmails := []string{
-	//	`abc@abc_def.com`,
+		`abc@abc_def.com`,
 	}
 	for _, email := range invalidEmails {
 		valid := IsValid(email)
 
 		if valid {
-			t.Errorf("expected invalid email address:", email)
+			t.Errorf("expected invalid email address: %s", email)
+		}
+	}
+}
+
+/**
+ * Tests that Validate reports the reason, segment and offset.
+ */
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		email   string
+		err     error
+		segment string
+		offset  int
+	}{
+		{`jsmith@apache.org`, nil, ``, 0},
+		{` jsmith@apache.org `, nil, ``, 0},
+		{``, ErrEmpty, ``, 0},
+		{`   `, ErrEmpty, ``, 0},
+		{"jsmith@apäche.org", ErrNonASCII, `ä`, 9},
+		{"jsmith@apa\xffche.org", ErrInvalidUTF8, "\xff", 10},
+		{"jsmith\t@apache.org", ErrControlChar, "\t", 6},
+		{" joe\x00@apache.org", ErrControlChar, "\x00", 4},
+		{"joe@apache\x7F.org", ErrControlChar, "\x7F", 10},
+		{"joe\u0085@apache.org", ErrControlChar, "\u0085", 3},
+		{` jsmithapache.org`, ErrMissingAt, `jsmithapache.org`, 1},
+		{`@apache.org`, ErrInvalidLocalPart, ``, 0},
+		{`jsmith@`, ErrInvalidDomain, ``, 7},
+		{`jsmith@apache.org.`, ErrTrailingDot, `.`, 17},
+		{`joe..ok@apache.org`, ErrInvalidLocalPart, `joe..ok`, 0},
+		{` joe blow@apache.org`, ErrInvalidLocalPart, `joe blow`, 1},
+		{`jsmith@apache.rog`, ErrUnknownTLD, `rog`, 14},
+		{`jsmith@apa_che.org`, ErrInvalidDomain, `apa_che`, 7},
+		{`jsmith@` + strings.Repeat(`a`, 64) + `.org`, ErrLabelTooLong, strings.Repeat(`a`, 64), 7},
+		{`jsmith@[300.1.1.1]`, ErrInvalidAddressLiteral, `[300.1.1.1]`, 7},
+		{`jsmith@[IPv6:2001:db8::g]`, ErrInvalidAddressLiteral, `[IPv6:2001:db8::g]`, 7},
+	}
+	for _, test := range tests {
+		err := Validate(test.email)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, expected nil", test.email, err)
+			}
+			continue
 		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("Validate(%q) = %v, expected %v", test.email, err, test.err)
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Validate(%q) = %T, expected *ValidationError", test.email, err)
+			continue
+		}
+		if verr.Segment != test.segment || verr.Offset != test.offset {
+			t.Errorf("Validate(%q) = %q at %d, expected %q at %d",
+				test.email, verr.Segment, verr.Offset, test.segment, test.offset)
+		}
+		if IsValid(test.email) {
+			t.Errorf("expected invalid email address: %s", test.email)
+		}
+	}
+
+	// Address literals are reported as such, not as domain names
+	want := `emailvalidator: invalid address literal: "[300.1.1.1]" at offset 7`
+	if err := Validate(`jsmith@[300.1.1.1]`); err == nil || err.Error() != want {
+		t.Errorf("Validate error = %v, expected %s", err, want)
+	}
+}
+
+/**
+ * Tests checkChars over every code point, and over byte sequences
+ * that aren't valid UTF-8.
+ */
+func TestCheckChars(t *testing.T) {
+	ranges := []struct {
+		lo, hi  rune
+		err     error // without AllowUTF8
+		utf8Err error // with AllowUTF8
+	}{
+		{0x00, 0x1F, ErrControlChar, ErrControlChar}, // C0 controls
+		{0x20, 0x7E, nil, nil},                       // printable ASCII
+		{0x7F, 0x7F, ErrControlChar, ErrControlChar}, // DEL
+		{0x80, 0x9F, ErrControlChar, ErrControlChar}, // C1 controls
+		{0xA0, 0x7FF, ErrNonASCII, nil},              // two byte sequences
+		{0x800, 0xD7FF, ErrNonASCII, nil},            // three byte sequences
+		{0xE000, 0xFFFF, ErrNonASCII, nil},           // three byte sequences after the surrogates
+		{0x10000, utf8.MaxRune, ErrNonASCII, nil},    // four byte sequences
+	}
+	for _, r := range ranges {
+		for c := r.lo; c <= r.hi; c++ {
+			s := "a" + string(c)
+			for _, mode := range []struct {
+				allowUTF8 bool
+				err       error
+			}{{false, r.err}, {true, r.utf8Err}} {
+				err := checkChars(s, mode.allowUTF8, false)
+				if mode.err == nil {
+					if err != nil {
+						t.Fatalf("checkChars(%U, %v) = %v, expected nil", c, mode.allowUTF8, err)
+					}
+					continue
+				}
+				if err == nil || err.Err != mode.err || err.Segment != string(c) || err.Offset != 1 {
+					t.Fatalf("checkChars(%U, %v) = %v, expected %v at offset 1", c, mode.allowUTF8, err, mode.err)
+				}
+			}
+		}
+	}
+
+	invalid := []string{
+		"\x80",             // continuation byte
+		"\xbf",             // continuation byte
+		"\xc0\xaf",         // overlong /
+		"\xc1\xbf",         // overlong
+		"\xe0\x80\xaf",     // overlong /
+		"\xed\xa0\x80",     // surrogate U+D800
+		"\xed\xbf\xbf",     // surrogate U+DFFF
+		"\xf4\x90\x80\x80", // beyond U+10FFFF
+		"\xf8\x88\x80\x80\x80",
+		"\xe4\xbd", // truncated
+		"\xfe",
+		"\xff",
+	}
+	for _, s := range invalid {
+		for _, allowUTF8 := range []bool{false, true} {
+			err := checkChars("a"+s+"b", allowUTF8, false)
+			if err == nil || err.Err != ErrInvalidUTF8 || err.Offset != 1 || err.Segment != s[:1] {
+				t.Errorf("checkChars(%q, %v) = %v, expected %v at offset 1", s, allowUTF8, err, ErrInvalidUTF8)
+			}
+		}
+	}
+}
+
+/**
+ * Tests the RFC 5321 length limits and the options that change them.
+ */
+func TestEmailLengths(t *testing.T) {
+	local64 := strings.Repeat(`a`, 64)
+	label63 := strings.Repeat(`b`, 63)
+	// 189 octets, so that local64@domain189 is 254 octets long
+	domain189 := label63 + `.` + label63 + `.` + strings.Repeat(`c`, 57) + `.com`
+
+	tests := []struct {
+		validator *EmailValidator
+		email     string
+		err       error
+		segment   string
+		offset    int
+	}{
+		{New(), local64 + `@apache.org`, nil, ``, 0},
+		{New(), local64 + `a@apache.org`, ErrLocalPartTooLong, local64 + `a`, 0},
+		{New(), local64 + `@` + domain189, nil, ``, 0},
+		{New(), local64 + `@c.` + domain189, ErrAddressTooLong, local64 + `@c.` + domain189, 0},
+		{New(), ` a@` + strings.Repeat(label63+`.`, 4) + `com`, ErrDomainTooLong, strings.Repeat(label63+`.`, 4) + `com`, 3},
+		{New(), `jsmith@` + label63 + `b.org`, ErrLabelTooLong, label63 + `b`, 7},
+		{New(MaxLabelLength(10)), `jsmith@abcdefghijk.com`, ErrLabelTooLong, `abcdefghijk`, 7},
+		{New(MaxLabelLength(10)), `jsmith@[192.168.100.100]`, nil, ``, 0},
+		{New(MaxLocalPartLength(8)), `jsmith@apache.org`, nil, ``, 0},
+		{New(MaxLocalPartLength(8)), `jsmith.ok@apache.org`, ErrLocalPartTooLong, `jsmith.ok`, 0},
+		{New(MaxDomainLength(9)), `jsmith@apache.org`, ErrDomainTooLong, `apache.org`, 7},
+		{New(MaxAddressLength(16)), `jsmith@apache.org`, ErrAddressTooLong, `jsmith@apache.org`, 0},
+		{New(MaxLocalPartLength(0), MaxAddressLength(0)), strings.Repeat(local64, 2) + `@` + domain189, nil, ``, 0},
+	}
+	for _, test := range tests {
+		err := test.validator.Validate(test.email)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, expected nil", test.email, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.Is(err, test.err) || !errors.As(err, &verr) {
+			t.Errorf("Validate(%q) = %v, expected %v", test.email, err, test.err)
+			continue
+		}
+		if verr.Segment != test.segment || verr.Offset != test.offset {
+			t.Errorf("Validate(%q) = %q at %d, expected %q at %d",
+				test.email, verr.Segment, verr.Offset, test.segment, test.offset)
+		}
+	}
+}
+
+/**
+ * Tests the RFC 5322 addr-spec syntax accepted in strict mode.
+ */
+func TestEmailStrict(t *testing.T) {
+	strict := New(Strict(true))
+
+	validEmails := []string{
+		`jsmith@apache.org`,
+		`andy.o'reilly@data-workshop.com`,
+		`!#$%&'*+-/=?^_{|}~@apache.org`,
+		"`a`@apache.org",
+		`"a\"b"@x.com`,
+		`"a\\b"@x.com`,
+		`"joe blow"@apache.org`,
+		`"joe@blow"@apache.org`,
+		`""@apache.org`,
+		`"\ "@apache.org`,
+		`john(comment)@apache.org`,
+		`(comment)john@apache.org`,
+		`john@(comment)apache.org`,
+		`john@apache.org(comment)`,
+		`john(nested (comment) \) here)@apache.org`,
+		` john @ apache.org `,
+		"john\r\n @apache.org",
+		"\"folded\r\n\tquote\"@apache.org",
+		"john@apache.org (c\r\n omment)",
+		`john@[192.168.1.1]`,
+		`john@[IPv6:2001:db8::1]`,
+	}
+	for _, email := range validEmails {
+		if err := strict.Validate(email); err != nil {
+			t.Errorf("expected valid email address in strict mode: %q: %v", email, err)
+		}
+	}
+
+	invalidEmails := []string{
+		`"a"b"@x.com`,
+		`"a\"@x.com`,
+		`"a"."b"@x.com`, // obsolete local part
+		`a."b"@x.com`,
+		`john.(comment)doe@x.com`,
+		`john..doe@apache.org`,
+		`.john@apache.org`,
+		`john.@apache.org`,
+		`john(comment@apache.org`,
+		`john(comment))@apache.org`,
+		`john doe@apache.org`,
+		`john@apache.org.`,
+		`john@apache..org`,
+		`john@apache.rog`,
+		`john@[192.168.1.1`,
+		`john@[192.168.1.a]`,
+		`john@[1.2.[3].4]`,
+		`john@[1.2.3.4]x`,
+		"john\r\n@apache.org", // CRLF must be followed by white space
+		"john\n @apache.org",
+		"\"joe\x00\"@apache.org",
+		`jo,hn@apache.org`,
+		`jo<hn@apache.org`,
+		`joe@apache.org@`,
+		`joe`,
+		`@apache.org`,
+		`  `,
+		`用户@例子.公司`,
+	}
+	for _, email := range invalidEmails {
+		if strict.IsValid(email) {
+			t.Errorf("expected invalid email address in strict mode: %q", email)
+		}
+	}
+
+	// RFC 6532 UTF-8 in every part of the syntax
+	utf8Strict := New(Strict(true), AllowUTF8(true))
+	for _, email := range []string{`用户@例子.公司`, `"用 户"@例子.公司`, `用户(コメント)@例子.公司`} {
+		if err := utf8Strict.Validate(email); err != nil {
+			t.Errorf("expected valid email address in strict UTF-8 mode: %q: %v", email, err)
+		}
+	}
+
+	tests := []struct {
+		email   string
+		err     error
+		segment string
+		offset  int
+	}{
+		{`"a"b"@x.com`, ErrInvalidLocalPart, `b`, 3},
+		{`john(comment@apache.org`, ErrInvalidLocalPart, `(comment@apache.org`, 4},
+		{`joe`, ErrMissingAt, `joe`, 0},
+		{`john..doe@apache.org`, ErrInvalidLocalPart, `.`, 5},
+		{`john@[1.2.3.4`, ErrInvalidAddressLiteral, `[1.2.3.4`, 5},
+		{` john @ apache.rog`, ErrUnknownTLD, `rog`, 15},
+		{`(c)` + strings.Repeat(`a`, 65) + `@apache.org`, ErrLocalPartTooLong, strings.Repeat(`a`, 65), 3},
+		{"john\r\n@apache.org", ErrInvalidLocalPart, "\r", 4},
+		{"joe\x00@apache.org", ErrControlChar, "\x00", 3},
+	}
+	for _, test := range tests {
+		err := strict.Validate(test.email)
+		var verr *ValidationError
+		if !errors.Is(err, test.err) || !errors.As(err, &verr) {
+			t.Errorf("Validate(%q) = %v, expected %v", test.email, err, test.err)
+			continue
+		}
+		if verr.Segment != test.segment || verr.Offset != test.offset {
+			t.Errorf("Validate(%q) = %q at %d, expected %q at %d",
+				test.email, verr.Segment, verr.Offset, test.segment, test.offset)
+		}
+	}
+}
+
+/**
+ * Tests that Parse splits addresses as Validate does.
+ */
+func TestParse(t *testing.T) {
+	tests := []struct {
+		validator *EmailValidator
+		email     string
+		want      Address
+	}{
+		{defaultValidator, `jsmith@apache.org`, Address{LocalPart: `jsmith`, Domain: `apache.org`}},
+		{defaultValidator, ` jsmith@apache.org `, Address{LocalPart: `jsmith`, Domain: `apache.org`}},
+		{defaultValidator, `"joe@blow"@apache.org`, Address{LocalPart: `"joe@blow"`, Domain: `apache.org`, Quoted: true}},
+		{defaultValidator, `"joe"."blow"@apache.org`, Address{LocalPart: `"joe"."blow"`, Domain: `apache.org`}},
+		{defaultValidator, `joe@[192.168.1.1]`, Address{LocalPart: `joe`, Domain: `[192.168.1.1]`, IsIPLiteral: true, IP: net.ParseIP(`192.168.1.1`)}},
+		{defaultValidator, `joe@[IPv6:2001:db8::1]`, Address{LocalPart: `joe`, Domain: `[IPv6:2001:db8::1]`, IsIPLiteral: true, IP: net.ParseIP(`2001:db8::1`)}},
+		{New(Strict(true)), `(c) "a\"b" (d) @ (e) apache.org (f)`, Address{LocalPart: `"a\"b"`, Domain: `apache.org`, Quoted: true}},
+		{New(Strict(true)), `joe@[ipv6:::1]`, Address{LocalPart: `joe`, Domain: `[ipv6:::1]`, IsIPLiteral: true, IP: net.IPv6loopback}},
+		{New(AllowUTF8(true)), `用户@例子.公司`, Address{LocalPart: `用户`, Domain: `例子.公司`}},
+	}
+	for _, test := range tests {
+		addr, err := test.validator.Parse(test.email)
+		if err != nil {
+			t.Errorf("Parse(%q) returned %v", test.email, err)
+			continue
+		}
+		if addr.LocalPart != test.want.LocalPart || addr.Domain != test.want.Domain ||
+			addr.IsIPLiteral != test.want.IsIPLiteral || !addr.IP.Equal(test.want.IP) || addr.Quoted != test.want.Quoted {
+			t.Errorf("Parse(%q) = %+v, expected %+v", test.email, addr, test.want)
+		}
+	}
+
+	invalidEmails := []string{``, `jsmith@apache.rog`, `joe@[300.1.1.1]`, `joe..blow@apache.org`}
+	for _, email := range invalidEmails {
+		addr, err := Parse(email)
+		if err == nil {
+			t.Errorf("Parse(%q) = %+v, expected an error", email, addr)
+		}
+		if !errors.Is(err, Validate(email).(*ValidationError).Err) {
+			t.Errorf("Parse(%q) returned %v, Validate returned %v", email, err, Validate(email))
+		}
+	}
+}
+
+/**
+ * Tests Normalize and that its results are valid.
+ */
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		validator *EmailValidator
+		email     string
+		opts      []NormalizeOption
+		want      string
+	}{
+		{defaultValidator, ` JSmith@Apache.ORG `, nil, `JSmith@apache.org`},
+		{defaultValidator, `"jsmith"@apache.org`, nil, `jsmith@apache.org`},
+		{defaultValidator, `"j.smith"@apache.org`, nil, `j.smith@apache.org`},
+		{defaultValidator, `"joe blow"@apache.org`, nil, `"joe blow"@apache.org`},
+		{defaultValidator, `".joe"@apache.org`, nil, `".joe"@apache.org`},
+		{defaultValidator, `joe@[IPv6:2001:DB8:0:0::1]`, nil, `joe@[IPv6:2001:db8::1]`},
+		{defaultValidator, `joe@[192.168.1.1]`, nil, `joe@[192.168.1.1]`},
+		{defaultValidator, `joe@[IPv6:::ffff:1.2.3.4]`, nil, `joe@[IPv6:::ffff:1.2.3.4]`},
+		{defaultValidator, `joe@[ipv6:::FFFF:1.2.3.4]`, nil, `joe@[IPv6:::ffff:1.2.3.4]`},
+		{defaultValidator, `joe@XN--MNCHEN-3YA.DE`, nil, `joe@xn--mnchen-3ya.de`},
+		{defaultValidator, `joe@xn--mnchen-3ya.de`, []NormalizeOption{UnicodeDomain(true)}, `joe@xn--mnchen-3ya.de`},
+		{New(AllowUTF8(true)), `joe@MÜNCHEN.de`, nil, `joe@xn--mnchen-3ya.de`},
+		{New(AllowUTF8(true)), `joe@xn--mnchen-3ya.de`, []NormalizeOption{UnicodeDomain(true)}, `joe@münchen.de`},
+		{New(AllowUTF8(true)), `"用户"@例子.公司`, []NormalizeOption{UnicodeDomain(true)}, `用户@例子.公司`},
+		{New(Strict(true)), `(c) "a\.b" @ apache.org`, nil, `a.b@apache.org`},
+		{New(Strict(true)), `"a\"b"@apache.org`, nil, `"a\"b"@apache.org`},
+		{New(AllowTld(true)), `root@COM`, nil, `root@com`},
+		{defaultValidator, `Joe+News@apache.org`, []NormalizeOption{RemoveSubaddress(true)}, `Joe@apache.org`},
+		{defaultValidator, `+news@apache.org`, []NormalizeOption{RemoveSubaddress(true)}, `+news@apache.org`},
+		{defaultValidator, `"joe+news"@apache.org`, []NormalizeOption{RemoveSubaddress(true)}, `joe@apache.org`},
+		{defaultValidator, `J.Smith+spam@GoogleMail.com`, []NormalizeOption{GmailRules(true)}, `jsmith@gmail.com`},
+		{defaultValidator, `J.Smith+spam@gmail.com`, nil, `J.Smith+spam@gmail.com`},
+		{defaultValidator, `J.Smith+spam@apache.org`, []NormalizeOption{GmailRules(true)}, `J.Smith+spam@apache.org`},
+	}
+	for _, test := range tests {
+		got, err := test.validator.Normalize(test.email, test.opts...)
+		if err != nil {
+			t.Errorf("Normalize(%q) returned %v", test.email, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Normalize(%q) = %q, expected %q", test.email, got, test.want)
+		}
+		if !test.validator.IsValid(got) {
+			t.Errorf("Normalize(%q) = %q, which is invalid", test.email, got)
+		}
+	}
+
+	if _, err := Normalize(`jsmith@apache.rog`); !errors.Is(err, ErrUnknownTLD) {
+		t.Errorf("Normalize(%q) returned %v, expected %v", `jsmith@apache.rog`, err, ErrUnknownTLD)
+	}
+}
+
+/**
+ * Tests parsing of name-addr mailboxes and address lists.
+ */
+func TestParseAddressList(t *testing.T) {
+	tests := []struct {
+		list string
+		want []Mailbox
+	}{
+		{`"Jane Doe" <jane@example.com>, bob@example.org`, []Mailbox{
+			{Name: `Jane Doe`, Address: Address{LocalPart: `jane`, Domain: `example.com`}},
+			{Address: Address{LocalPart: `bob`, Domain: `example.org`}},
+		}},
+		{`John Q. Public <jqp@example.com>`, []Mailbox{
+			{Name: `John Q. Public`, Address: Address{LocalPart: `jqp`, Domain: `example.com`}},
+		}},
+		{`"Doe, Jane" (work) <jane@example.com> (home), <bob@example.org>`, []Mailbox{
+			{Name: `Doe, Jane`, Address: Address{LocalPart: `jane`, Domain: `example.com`}},
+			{Address: Address{LocalPart: `bob`, Domain: `example.org`}},
+		}},
+		{`"a \"b\" c" <"joe@blow"@example.com>`, []Mailbox{
+			{Name: `a "b" c`, Address: Address{LocalPart: `"joe@blow"`, Domain: `example.com`, Quoted: true}},
+		}},
+		{"Jane\r\n Doe <jane@example.com>", []Mailbox{
+			{Name: `Jane Doe`, Address: Address{LocalPart: `jane`, Domain: `example.com`}},
+		}},
+		{`Team: a@example.com, Bob <b@example.com>; , c@example.org`, []Mailbox{
+			{Address: Address{LocalPart: `a`, Domain: `example.com`}, Group: `Team`},
+			{Name: `Bob`, Address: Address{LocalPart: `b`, Domain: `example.com`}, Group: `Team`},
+			{Address: Address{LocalPart: `c`, Domain: `example.org`}},
+		}},
+		{`undisclosed-recipients:;`, nil},
+	}
+	for _, test := range tests {
+		got, err := ParseAddressList(test.list)
+		if err != nil {
+			t.Errorf("ParseAddressList(%q) returned %v", test.list, err)
+			continue
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("ParseAddressList(%q) = %+v, expected %+v", test.list, got, test.want)
+			continue
+		}
+		for i := range got {
+			g, w := got[i], test.want[i]
+			if g.Name != w.Name || g.Group != w.Group || g.Address.LocalPart != w.Address.LocalPart ||
+				g.Address.Domain != w.Address.Domain || g.Address.Quoted != w.Address.Quoted {
+				t.Errorf("ParseAddressList(%q)[%d] = %+v, expected %+v", test.list, i, g, w)
+			}
+		}
+	}
+
+	errorTests := []struct {
+		list    string
+		err     error
+		index   int
+		segment string
+		offset  int
+	}{
+		{``, ErrEmpty, 0, ``, 0},
+		{`a@example.com,`, ErrEmpty, 1, ``, 14},
+		{`a@example.com,,b@example.com`, ErrEmpty, 1, ``, 14},
+		{`a@example.com, Bob <bob@example.rog>`, ErrUnknownTLD, 1, `rog`, 32},
+		{`a@example.com, b@example.com, c@@example.com`, ErrInvalidLocalPart, 2, `c@`, 30},
+		{`a@example.com, Bob <bob@example.com`, ErrInvalidMailbox, 1, `<bob@example.com`, 19},
+		{`"Jane <jane@example.com>`, ErrInvalidMailbox, 0, `"Jane <jane@example.com>`, 0},
+		{`Jane @ Doe <jane@example.com>`, ErrInvalidDisplayName, 0, `@`, 5},
+		{`.Jane <jane@example.com>`, ErrInvalidDisplayName, 0, `.`, 0},
+		{`Jane <jane@example.com> Doe`, ErrInvalidMailbox, 0, ` Doe`, 23},
+		{`Team: a@example.com`, ErrInvalidMailbox, 1, `Team: a@example.com`, 0},
+		{`a@example.com; b@example.com`, ErrInvalidLocalPart, 0, `a@example.com; b`, 0},
+		{`Team: a@example.com; x, b@example.com`, ErrInvalidMailbox, 1, ` x`, 20},
+	}
+	for _, test := range errorTests {
+		err := ValidateAddressList(test.list)
+		var lerr *ListError
+		if !errors.Is(err, test.err) || !errors.As(err, &lerr) {
+			t.Errorf("ValidateAddressList(%q) = %v, expected %v", test.list, err, test.err)
+			continue
+		}
+		if lerr.Index != test.index || lerr.Err.Segment != test.segment || lerr.Err.Offset != test.offset {
+			t.Errorf("ValidateAddressList(%q) = element %d, %q at %d, expected element %d, %q at %d",
+				test.list, lerr.Index, lerr.Err.Segment, lerr.Err.Offset, test.index, test.segment, test.offset)
+		}
+	}
+
+	m, err := ParseMailbox(` "Jane Doe" <jane@example.com> `)
+	if err != nil || m.Name != `Jane Doe` || m.Address.LocalPart != `jane` || m.Address.Domain != `example.com` {
+		t.Errorf("ParseMailbox = %+v, %v", m, err)
+	}
+	if _, err := ParseMailbox(`jane@example.com, bob@example.org`); err == nil {
+		t.Errorf("ParseMailbox accepted a list")
+	}
+
+	// The addr-specs follow the validator's rules
+	strict := New(Strict(true))
+	if err := strict.ValidateAddressList(`Jane <jane(home)@example.com>, "a\"b"@example.com`); err != nil {
+		t.Errorf("strict ValidateAddressList returned %v", err)
+	}
+	if err := ValidateAddressList(`Jane <jane(home)@example.com>`); err == nil {
+		t.Errorf("ValidateAddressList accepted a comment in the default mode")
+	}
+	if err := ValidateAddressList(`José <jose@example.com>`); !errors.Is(err, ErrNonASCII) {
+		t.Errorf("ValidateAddressList(%q) = %v, expected %v", `José <jose@example.com>`, err, ErrNonASCII)
+	}
+	if err := New(AllowUTF8(true)).ValidateAddressList(`José <用户@例子.公司>`); err != nil {
+		t.Errorf("UTF-8 ValidateAddressList returned %v", err)
+	}
+}
+
+/**
+ * Tests that splitAddress agrees with EMAIL_REGEX.
+ */
+func TestSplitAddress(t *testing.T) {
+	emailRegex := regexp.MustCompile(EMAIL_REGEX)
+	emails := []string{
+		`jsmith@apache.org`,
+		`a@b`,
+		`a@b@c`,
+		`a@b@`,
+		`a@@b`,
+		`@a`,
+		`a@`,
+		`@`,
+		`@@`,
+		`a`,
+		``,
+		"a\n@b",
+		"a@b\n",
+		`"a@b"@c`,
+	}
+	for _, email := range emails {
+		user, domain, ok := splitAddress(email)
+		groups := emailRegex.FindStringSubmatch(email)
+		if ok != (groups != nil) {
+			t.Errorf("splitAddress(%q) ok = %v, EMAIL_REGEX match = %v", email, ok, groups != nil)
+			continue
+		}
+		if ok && (user != groups[1] || domain != groups[2]) {
+			t.Errorf("splitAddress(%q) = %q, %q, EMAIL_REGEX = %q, %q", email, user, domain, groups[1], groups[2])
+		}
+	}
+}
+
+func BenchmarkIsValid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValid(`andy.noble@data-workshop.com`)
+	}
+}
+
+func BenchmarkIsValidIPAddress(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValid(`someone@[216.109.118.76]`)
+	}
+}
+
+func BenchmarkIsValidInvalid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValid(`joe..ok@apache.org`)
 	}
 }
@@ -0,0 +1,188 @@
+// Copyright 2013 Doug Sparling. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emailvalidator
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/dsparling/go-commons-validator/domainvalidator"
+)
+
+/**
+ * NormalizeOption configures Normalize.
+ */
+type NormalizeOption func(*normalizeOptions)
+
+type normalizeOptions struct {
+	unicodeDomain    bool
+	removeSubaddress bool
+	gmailRules       bool
+}
+
+/**
+ * UnicodeDomain sets whether internationalized domains are returned in
+ * Unicode rather than as A-labels. Only validators that AllowUTF8
+ * accept Unicode domains, so others always return A-labels.
+ * @param unicodeDomain Should domains be returned in Unicode?
+ */
+func UnicodeDomain(unicodeDomain bool) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.unicodeDomain = unicodeDomain
+	}
+}
+
+/**
+ * RemoveSubaddress sets whether a +tag subaddress (RFC 5233) is removed
+ * from the local part, as in joe+news@example.com to joe@example.com.
+ * @param removeSubaddress Should +tags be removed?
+ */
+func RemoveSubaddress(removeSubaddress bool) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.removeSubaddress = removeSubaddress
+	}
+}
+
+/**
+ * GmailRules sets whether the rules of Gmail are applied to gmail.com
+ * and googlemail.com addresses: the local part is lower cased, dots and
+ * a +tag are removed from it, and the domain becomes gmail.com.
+ * @param gmailRules Should the Gmail rules be applied?
+ */
+func GmailRules(gmailRules bool) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.gmailRules = gmailRules
+	}
+}
+
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+/**
+ * Normalizes an e-mail address using the default validator. The
+ * result always passes IsValid.
+ * @param emailAddress The value normalization is being performed on.
+ * @param opts the options to apply
+ * @return the normalized address, and nil if the email address is
+ * valid, otherwise a *ValidationError.
+ */
+func Normalize(emailAddress string, opts ...NormalizeOption) (string, error) {
+	return defaultValidator.Normalize(emailAddress, opts...)
+}
+
+/**
+ * Normalizes an e-mail address to a canonical form, so that addresses
+ * of the same mailbox compare equal. Surrounding whitespace (and, in
+ * strict mode, comments) is removed, the domain is lower cased with
+ * internationalized labels as A-labels, IP literals are written in their
+ * shortest form and quotes that aren't needed are removed from the local
+ * part. The local part is otherwise left alone unless provider rules are
+ * requested, since its case may matter. The result always passes
+ * IsValid of this validator.
+ * @param emailAddress The value normalization is being performed on.
+ * @param opts the options to apply
+ * @return the normalized address, and nil if the email address is
+ * valid, otherwise a *ValidationError.
+ */
+func (v *EmailValidator) Normalize(emailAddress string, opts ...NormalizeOption) (string, error) {
+	var o normalizeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	addr, err := v.Parse(emailAddress)
+	if err != nil {
+		return "", err
+	}
+
+	localPart, quoted := addr.LocalPart, addr.Quoted
+	if quoted {
+		if content := v.unquote(localPart); isDotAtom(content) && (!v.allowUTF8 || isPrintable(content)) {
+			localPart, quoted = content, false
+		}
+	}
+
+	domain := v.normalizeDomain(addr, o.unicodeDomain && v.allowUTF8)
+
+	if !quoted {
+		if o.gmailRules && gmailDomains[domain] {
+			localPart = strings.ToLower(strings.Replace(removeSubaddress(localPart), ".", "", -1))
+			domain = "gmail.com"
+		} else if o.removeSubaddress {
+			localPart = removeSubaddress(localPart)
+		}
+	}
+
+	normalized := localPart + "@" + domain
+	if err := v.Validate(normalized); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// normalizeDomain returns the domain of addr in lower case, in Unicode
+// if unicode is set and otherwise with A-labels. IP literals are
+// rewritten from the parsed address in the form their tag gives, so
+// [IPv6:::ffff:1.2.3.4] stays an IPv6 literal.
+func (v *EmailValidator) normalizeDomain(addr Address, unicode bool) string {
+	if addr.IsIPLiteral {
+		if hasIPv6Tag(addr.Domain[1 : len(addr.Domain)-1]) {
+			ip, _ := netip.AddrFromSlice(addr.IP.To16())
+			return "[" + IPV6_TAG + ip.String() + "]"
+		}
+		return "[" + addr.IP.String() + "]"
+	}
+	d, err := v.domains().Parse(addr.Domain)
+	if err != nil {
+		// A bare TLD, allowed by AllowTld
+		return strings.ToLower(domainvalidator.UnicodeToASCII(addr.Domain))
+	}
+	if unicode {
+		return d.Unicode
+	}
+	return d.ASCII
+}
+
+// unquote returns the content of a quoted local part. In strict mode
+// quoted pairs stand for the character they escape and folding is
+// removed; the pragmatic QUOTED_USER has neither.
+func (v *EmailValidator) unquote(localPart string) string {
+	content := localPart[1 : len(localPart)-1]
+	if !v.strict {
+		return content
+	}
+	return unquotePairs(content)
+}
+
+// isDotAtom reports whether s is an RFC 5322 dot-atom-text, which needs
+// no quotes.
+func isDotAtom(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, atom := range strings.Split(s, ".") {
+		if atom == "" {
+			return false
+		}
+		for i := 0; i < len(atom); i++ {
+			if !isAtext(atom[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// removeSubaddress removes a +tag from localPart, unless that would
+// leave it empty.
+func removeSubaddress(localPart string) string {
+	if plus := strings.IndexByte(localPart, '+'); plus > 0 {
+		return localPart[:plus]
+	}
+	return localPart
+}
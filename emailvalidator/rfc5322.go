@@ -0,0 +1,316 @@
+// Copyright 2013 Doug Sparling. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emailvalidator
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// addrSpec holds the parts of an RFC 5322 addr-spec, without the
+// comments and folding white space around them. The offsets locate
+// the parts in the input.
+type addrSpec struct {
+	localPart    string
+	localOffset  int
+	domain       string
+	domainOffset int
+	quoted       bool // local part is a quoted-string
+	literal      bool // domain is a domain-literal
+}
+
+/*
+ * parseStrict parses emailAddress as an RFC 5322 addr-spec (section
+ * 3.4.1), then applies the length limits and domain checks of Validate.
+ * The obsolete syntax of section 4 is not accepted. With AllowUTF8 the
+ * RFC 6532 extensions allow non-ASCII characters wherever atext, qtext,
+ * ctext and dtext are allowed.
+ */
+func (v *EmailValidator) parseStrict(emailAddress string) (addrSpec, *ValidationError) {
+	if strings.TrimSpace(emailAddress) == "" {
+		return addrSpec{}, &ValidationError{Err: ErrEmpty}
+	}
+	if err := checkChars(emailAddress, v.allowUTF8, true); err != nil {
+		return addrSpec{}, err
+	}
+	spec, err := parseAddrSpec(emailAddress)
+	if err != nil {
+		return addrSpec{}, err
+	}
+	if err := v.checkLengths(spec.localPart, spec.localOffset, spec.domain, spec.domainOffset); err != nil {
+		return addrSpec{}, err
+	}
+	if err := v.validateDomain(spec.domain); err != nil {
+		err.Offset += spec.domainOffset
+		return addrSpec{}, err
+	}
+	return spec, nil
+}
+
+// addrSpecParser is a recursive descent parser over an input already
+// checked by checkChars, so any non-ASCII bytes are valid UTF-8 that
+// the caller allows.
+type addrSpecParser struct {
+	s   string
+	pos int
+}
+
+/*
+ * parseAddrSpec parses s as
+ *
+ *	addr-spec  = local-part "@" domain
+ *	local-part = dot-atom / quoted-string
+ *	domain     = dot-atom / domain-literal
+ */
+func parseAddrSpec(s string) (addrSpec, *ValidationError) {
+	var spec addrSpec
+	p := &addrSpecParser{s: s}
+
+	if err := p.cfws(ErrInvalidLocalPart); err != nil {
+		return spec, err
+	}
+	spec.localOffset = p.pos
+	if p.peek('"') {
+		spec.quoted = true
+		if err := p.quotedString(ErrInvalidLocalPart); err != nil {
+			return spec, err
+		}
+	} else if err := p.dotAtomText(ErrInvalidLocalPart); err != nil {
+		return spec, err
+	}
+	spec.localPart = s[spec.localOffset:p.pos]
+	if err := p.cfws(ErrInvalidLocalPart); err != nil {
+		return spec, err
+	}
+
+	if !p.peek('@') {
+		if p.pos == len(s) {
+			return spec, &ValidationError{Err: ErrMissingAt, Segment: s, Offset: 0}
+		}
+		return spec, p.errorAt(ErrInvalidLocalPart)
+	}
+	p.pos++
+
+	if err := p.cfws(ErrInvalidDomain); err != nil {
+		return spec, err
+	}
+	spec.domainOffset = p.pos
+	if p.peek('[') {
+		spec.literal = true
+		if err := p.domainLiteral(); err != nil {
+			return spec, err
+		}
+	} else if err := p.dotAtomText(ErrInvalidDomain); err != nil {
+		return spec, err
+	}
+	spec.domain = s[spec.domainOffset:p.pos]
+	if err := p.cfws(ErrInvalidDomain); err != nil {
+		return spec, err
+	}
+
+	if p.pos < len(s) {
+		return spec, p.errorAt(ErrInvalidDomain)
+	}
+	return spec, nil
+}
+
+func (p *addrSpecParser) peek(c byte) bool {
+	return p.pos < len(p.s) && p.s[p.pos] == c
+}
+
+// errorAt reports the character at the current position.
+func (p *addrSpecParser) errorAt(err error) *ValidationError {
+	if p.pos == len(p.s) {
+		return &ValidationError{Err: err, Offset: p.pos}
+	}
+	_, size := utf8.DecodeRuneInString(p.s[p.pos:])
+	return &ValidationError{Err: err, Segment: p.s[p.pos : p.pos+size], Offset: p.pos}
+}
+
+// unterminated reports a quoted-string, comment or domain-literal that
+// starts at start but doesn't end.
+func (p *addrSpecParser) unterminated(err error, start int) *ValidationError {
+	return &ValidationError{Err: err, Segment: p.s[start:], Offset: start}
+}
+
+/*
+ * dotAtomText parses
+ *
+ *	dot-atom-text = 1*atext *("." 1*atext)
+ */
+func (p *addrSpecParser) dotAtomText(err error) *ValidationError {
+	for {
+		start := p.pos
+		for p.pos < len(p.s) && isAtext(p.s[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return p.errorAt(err)
+		}
+		if !p.peek('.') {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+/*
+ * quotedString parses
+ *
+ *	quoted-string = DQUOTE *([FWS] qcontent) [FWS] DQUOTE
+ *	qcontent      = qtext / quoted-pair
+ *
+ * Errors are reported as err.
+ */
+func (p *addrSpecParser) quotedString(err error) *ValidationError {
+	start := p.pos
+	p.pos++
+	for {
+		p.fws()
+		switch {
+		case p.pos == len(p.s):
+			return p.unterminated(err, start)
+		case p.peek('"'):
+			p.pos++
+			return nil
+		case p.peek('\\'):
+			if perr := p.quotedPair(err); perr != nil {
+				return perr
+			}
+		case isQtext(p.s[p.pos]):
+			p.pos++
+		default:
+			return p.errorAt(err)
+		}
+	}
+}
+
+/*
+ * quotedPair parses
+ *
+ *	quoted-pair = "\" (VCHAR / WSP)
+ */
+func (p *addrSpecParser) quotedPair(err error) *ValidationError {
+	p.pos++
+	if p.pos == len(p.s) || !(isVchar(p.s[p.pos]) || isWSP(p.s[p.pos])) {
+		return p.errorAt(err)
+	}
+	p.pos++
+	return nil
+}
+
+/*
+ * domainLiteral parses
+ *
+ *	domain-literal = "[" *([FWS] dtext) [FWS] "]"
+ */
+func (p *addrSpecParser) domainLiteral() *ValidationError {
+	start := p.pos
+	p.pos++
+	for {
+		p.fws()
+		switch {
+		case p.pos == len(p.s):
+			return p.unterminated(ErrInvalidAddressLiteral, start)
+		case p.peek(']'):
+			p.pos++
+			return nil
+		case isDtext(p.s[p.pos]):
+			p.pos++
+		default:
+			return p.errorAt(ErrInvalidAddressLiteral)
+		}
+	}
+}
+
+/*
+ * cfws skips optional comments and folding white space:
+ *
+ *	CFWS     = (1*([FWS] comment) [FWS]) / FWS
+ *	comment  = "(" *([FWS] ccontent) [FWS] ")"
+ *	ccontent = ctext / quoted-pair / comment
+ *
+ * Errors inside a comment are reported as err.
+ */
+func (p *addrSpecParser) cfws(err error) *ValidationError {
+	for {
+		p.fws()
+		if !p.peek('(') {
+			return nil
+		}
+		start := p.pos
+		p.pos++
+		for depth := 1; depth > 0; {
+			p.fws()
+			switch {
+			case p.pos == len(p.s):
+				return p.unterminated(err, start)
+			case p.peek('('):
+				depth++
+				p.pos++
+			case p.peek(')'):
+				depth--
+				p.pos++
+			case p.peek('\\'):
+				if perr := p.quotedPair(err); perr != nil {
+					return perr
+				}
+			case isCtext(p.s[p.pos]):
+				p.pos++
+			default:
+				return p.errorAt(err)
+			}
+		}
+	}
+}
+
+/*
+ * fws skips folding white space, if any:
+ *
+ *	FWS = ([*WSP CRLF] 1*WSP)
+ *
+ * A CRLF not followed by white space is left for the caller to reject.
+ */
+func (p *addrSpecParser) fws() {
+	for p.pos < len(p.s) && isWSP(p.s[p.pos]) {
+		p.pos++
+	}
+	if strings.HasPrefix(p.s[p.pos:], "\r\n") && p.pos+2 < len(p.s) && isWSP(p.s[p.pos+2]) {
+		p.pos += 2
+		for p.pos < len(p.s) && isWSP(p.s[p.pos]) {
+			p.pos++
+		}
+	}
+}
+
+// The character classes of RFC 5322 section 3.2. Bytes of non-ASCII
+// characters, only present with AllowUTF8, belong to every class but
+// WSP, as in RFC 6532 section 3.2.
+
+func isWSP(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isVchar(c byte) bool {
+	return '!' <= c && c <= '~' || c >= utf8.RuneSelf
+}
+
+func isAtext(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) >= 0 || c >= utf8.RuneSelf
+}
+
+func isQtext(c byte) bool {
+	return isVchar(c) && c != '"' && c != '\\'
+}
+
+func isCtext(c byte) bool {
+	return isVchar(c) && c != '(' && c != ')' && c != '\\'
+}
+
+func isDtext(c byte) bool {
+	return isVchar(c) && c != '[' && c != ']' && c != '\\'
+}
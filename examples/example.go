@@ -12,8 +12,12 @@ import (
 
 func main() {
 	// true
-	fmt.Println(email.IsValid("test@example.com"))
+	fmt.Println(emailvalidator.IsValid("test@example.com"))
 
 	// false
-	fmt.Println(email.IsValid("testexample.com"))
+	fmt.Println(emailvalidator.IsValid("testexample.com"))
+
+	// true
+	local := emailvalidator.New(emailvalidator.AllowLocal(true))
+	fmt.Println(local.IsValid("test@localhost"))
 }
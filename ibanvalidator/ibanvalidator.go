@@ -0,0 +1,610 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/IBANValidator.java?view=log
+ */
+package ibanvalidator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dsparling/go-commons-validator/checkdigit"
+	"github.com/dsparling/go-commons-validator/regexvalidator"
+)
+
+const (
+	MIN_LEN = 8
+	MAX_LEN = 34 // ECBS IBAN standard, including the country code
+)
+
+var (
+	ErrEmpty             = errors.New("ibanvalidator: empty IBAN")
+	ErrInvalidCharacter  = errors.New("ibanvalidator: invalid character")
+	ErrInvalidSpace      = errors.New("ibanvalidator: space outside the print format groups")
+	ErrUnknownCountry    = errors.New("ibanvalidator: unknown country code")
+	ErrInvalidLength     = errors.New("ibanvalidator: invalid length for country")
+	ErrInvalidFormat     = errors.New("ibanvalidator: invalid format for country")
+	ErrInvalidCheckDigit = errors.New("ibanvalidator: invalid check digits")
+	ErrInvalidValidator  = errors.New("ibanvalidator: validator not created by NewValidator")
+)
+
+/**
+ * ValidationError reports why an IBAN failed validation. Segment is
+ * the offending character for ErrInvalidCharacter and ErrInvalidSpace,
+ * the country code for ErrUnknownCountry, the check digits for
+ * ErrInvalidFormat and ErrInvalidCheckDigit when they are at fault, the
+ * BBAN for ErrInvalidFormat otherwise, and the whole IBAN for
+ * ErrInvalidLength. Segment is taken from the input as given, so in the
+ * print format it includes the spaces between groups. Offset is its
+ * byte offset in the string passed to Validate. ErrEmpty has neither.
+ */
+type ValidationError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * The validation details for a country.
+ */
+type Validator struct {
+	countryCode  string
+	lengthOfIBAN int
+	format       string
+	regex        *regexvalidator.RegexValidator
+}
+
+/**
+ * Creates the validator for a country.
+ * @param countryCode the country code
+ * @param length the length of the IBAN
+ * @param format the regex to use to check the format, which must
+ * start with the country code
+ * @return the validator, and nil, or an error if the country code,
+ * length or format is invalid
+ */
+func NewValidator(countryCode string, length int, format string) (*Validator, error) {
+	if len(countryCode) != 2 || !isUpper(countryCode[0]) || !isUpper(countryCode[1]) {
+		return nil, fmt.Errorf("ibanvalidator: invalid country code %q; must be exactly 2 upper-case characters", countryCode)
+	}
+	if length > MAX_LEN || length < MIN_LEN {
+		return nil, fmt.Errorf("ibanvalidator: invalid length %d, must be between %d and %d", length, MIN_LEN, MAX_LEN)
+	}
+	if !strings.HasPrefix(format, countryCode) {
+		return nil, fmt.Errorf("ibanvalidator: country code %q does not agree with format %q", countryCode, format)
+	}
+	regex, err := regexvalidator.New([]string{format})
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{
+		countryCode:  countryCode,
+		lengthOfIBAN: length,
+		format:       format,
+		regex:        regex,
+	}, nil
+}
+
+// mustNewValidator is NewValidator for the default formats, which are
+// known to be valid.
+func mustNewValidator(countryCode string, length int, format string) *Validator {
+	v, err := NewValidator(countryCode, length, format)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+/**
+ * Returns the country code of the validator.
+ * @return the country code
+ */
+func (v *Validator) CountryCode() string {
+	return v.countryCode
+}
+
+/**
+ * Returns the length of the IBANs of the country.
+ * @return the length of the IBAN
+ */
+func (v *Validator) LengthOfIBAN() int {
+	return v.lengthOfIBAN
+}
+
+/**
+ * Returns the regex that checks the format of the IBANs of the
+ * country.
+ * @return the format
+ */
+func (v *Validator) Format() string {
+	return v.format
+}
+
+/*
+ * Wikipedia [1] says that only uppercase is allowed.
+ * The SWIFT PDF file [2] implies that lower case is allowed.
+ * However there are no examples using lower-case.
+ * Unfortunately the relevant ISO documents (ISO 13616-1) are not
+ * available for free.
+ * The IBANCheckDigit code treats upper and lower case the same,
+ * so any case validation has to be done in this class.
+ *
+ * Note: the European Payments council has a document [3] which
+ * includes a description of the IBAN. Section 5 clearly states that
+ * only upper case is allowed. Also the maximum length is 34
+ * characters (including the country code), and the length is fixed
+ * for each country.
+ *
+ * It looks like lower-case is permitted in BBANs, but they must be
+ * converted to upper case for IBANs.
+ *
+ * The table holds the formats of the SWIFT IBAN registry [2]: those of
+ * Commons Validator 1.6, from the 2017 registry, and the countries the
+ * registry added since, from BI to YE. When the registry changes,
+ * SetValidator can add or replace countries.
+ *
+ * [1] https://en.wikipedia.org/wiki/International_Bank_Account_Number
+ * [2] http://www.swift.com/dsp/resources/documents/IBAN_Registry.pdf
+ * [3] http://www.europeanpaymentscouncil.eu/documents/ECBS%20IBAN%20standard%20EBS204_V3.2.pdf
+ */
+var defaultFormats = []*Validator{
+	mustNewValidator("AD", 24, "AD\\d{10}[A-Z0-9]{12}"),                 // Andorra
+	mustNewValidator("AE", 23, "AE\\d{21}"),                             // United Arab Emirates
+	mustNewValidator("AL", 28, "AL\\d{10}[A-Z0-9]{16}"),                 // Albania
+	mustNewValidator("AT", 20, "AT\\d{18}"),                             // Austria
+	mustNewValidator("AZ", 28, "AZ\\d{2}[A-Z]{4}[A-Z0-9]{20}"),          // Republic of Azerbaijan
+	mustNewValidator("BA", 20, "BA\\d{18}"),                             // Bosnia and Herzegovina
+	mustNewValidator("BE", 16, "BE\\d{14}"),                             // Belgium
+	mustNewValidator("BG", 22, "BG\\d{2}[A-Z]{4}\\d{6}[A-Z0-9]{8}"),     // Bulgaria
+	mustNewValidator("BH", 22, "BH\\d{2}[A-Z]{4}[A-Z0-9]{14}"),          // Bahrain (Kingdom of)
+	mustNewValidator("BI", 27, "BI\\d{25}"),                             // Burundi
+	mustNewValidator("BR", 29, "BR\\d{25}[A-Z]{1}[A-Z0-9]{1}"),          // Brazil
+	mustNewValidator("BY", 28, "BY\\d{2}[A-Z0-9]{4}\\d{4}[A-Z0-9]{16}"), // Republic of Belarus
+	mustNewValidator("CH", 21, "CH\\d{7}[A-Z0-9]{12}"),                  // Switzerland
+	mustNewValidator("CR", 22, "CR\\d{20}"),                             // Costa Rica
+	mustNewValidator("CY", 28, "CY\\d{10}[A-Z0-9]{16}"),                 // Cyprus
+	mustNewValidator("CZ", 24, "CZ\\d{22}"),                             // Czech Republic
+	mustNewValidator("DE", 22, "DE\\d{20}"),                             // Germany
+	mustNewValidator("DJ", 27, "DJ\\d{25}"),                             // Djibouti
+	mustNewValidator("DK", 18, "DK\\d{16}"),                             // Denmark
+	mustNewValidator("DO", 28, "DO\\d{2}[A-Z0-9]{4}\\d{20}"),            // Dominican Republic
+	mustNewValidator("EE", 20, "EE\\d{18}"),                             // Estonia
+	mustNewValidator("EG", 29, "EG\\d{27}"),                             // Egypt
+	mustNewValidator("ES", 24, "ES\\d{22}"),                             // Spain
+	mustNewValidator("FI", 18, "FI\\d{16}"),                             // Finland
+	mustNewValidator("FK", 18, "FK\\d{2}[A-Z]{2}\\d{12}"),               // Falkland Islands
+	mustNewValidator("FO", 18, "FO\\d{16}"),                             // Denmark (Faroes)
+	mustNewValidator("FR", 27, "FR\\d{12}[A-Z0-9]{11}\\d{2}"),           // France
+	mustNewValidator("GB", 22, "GB\\d{2}[A-Z]{4}\\d{14}"),               // United Kingdom
+	mustNewValidator("GE", 22, "GE\\d{2}[A-Z]{2}\\d{16}"),               // Georgia
+	mustNewValidator("GI", 23, "GI\\d{2}[A-Z]{4}[A-Z0-9]{15}"),          // Gibraltar
+	mustNewValidator("GL", 18, "GL\\d{16}"),                             // Denmark (Greenland)
+	mustNewValidator("GR", 27, "GR\\d{9}[A-Z0-9]{16}"),                  // Greece
+	mustNewValidator("GT", 28, "GT\\d{2}[A-Z0-9]{24}"),                  // Guatemala
+	mustNewValidator("HN", 28, "HN\\d{2}[A-Z]{4}\\d{20}"),               // Honduras
+	mustNewValidator("HR", 21, "HR\\d{19}"),                             // Croatia
+	mustNewValidator("HU", 28, "HU\\d{26}"),                             // Hungary
+	mustNewValidator("IE", 22, "IE\\d{2}[A-Z]{4}\\d{14}"),               // Ireland
+	mustNewValidator("IL", 23, "IL\\d{21}"),                             // Israel
+	mustNewValidator("IQ", 23, "IQ\\d{2}[A-Z]{4}\\d{15}"),               // Iraq
+	mustNewValidator("IS", 26, "IS\\d{24}"),                             // Iceland
+	mustNewValidator("IT", 27, "IT\\d{2}[A-Z]{1}\\d{10}[A-Z0-9]{12}"),   // Italy
+	mustNewValidator("JO", 30, "JO\\d{2}[A-Z]{4}\\d{4}[A-Z0-9]{18}"),    // Jordan
+	mustNewValidator("KW", 30, "KW\\d{2}[A-Z]{4}[A-Z0-9]{22}"),          // Kuwait
+	mustNewValidator("KZ", 20, "KZ\\d{5}[A-Z0-9]{13}"),                  // Kazakhstan
+	mustNewValidator("LB", 28, "LB\\d{6}[A-Z0-9]{20}"),                  // Lebanon
+	mustNewValidator("LC", 32, "LC\\d{2}[A-Z]{4}[A-Z0-9]{24}"),          // Saint Lucia
+	mustNewValidator("LI", 21, "LI\\d{7}[A-Z0-9]{12}"),                  // Liechtenstein (Principality of)
+	mustNewValidator("LT", 20, "LT\\d{18}"),                             // Lithuania
+	mustNewValidator("LU", 20, "LU\\d{5}[A-Z0-9]{13}"),                  // Luxembourg
+	mustNewValidator("LV", 21, "LV\\d{2}[A-Z]{4}[A-Z0-9]{13}"),          // Latvia
+	mustNewValidator("LY", 25, "LY\\d{23}"),                             // Libya
+	mustNewValidator("MC", 27, "MC\\d{12}[A-Z0-9]{11}\\d{2}"),           // Monaco
+	mustNewValidator("MD", 24, "MD\\d{2}[A-Z0-9]{20}"),                  // Moldova
+	mustNewValidator("ME", 22, "ME\\d{20}"),                             // Montenegro
+	mustNewValidator("MK", 19, "MK\\d{5}[A-Z0-9]{10}\\d{2}"),            // North Macedonia
+	mustNewValidator("MN", 20, "MN\\d{18}"),                             // Mongolia
+	mustNewValidator("MR", 27, "MR\\d{25}"),                             // Mauritania
+	mustNewValidator("MT", 31, "MT\\d{2}[A-Z]{4}\\d{5}[A-Z0-9]{18}"),    // Malta
+	mustNewValidator("MU", 30, "MU\\d{2}[A-Z]{4}\\d{19}[A-Z]{3}"),       // Mauritius
+	mustNewValidator("NI", 28, "NI\\d{2}[A-Z]{4}\\d{20}"),               // Nicaragua
+	mustNewValidator("NL", 18, "NL\\d{2}[A-Z]{4}\\d{10}"),               // The Netherlands
+	mustNewValidator("NO", 15, "NO\\d{13}"),                             // Norway
+	mustNewValidator("OM", 23, "OM\\d{5}[A-Z0-9]{16}"),                  // Oman
+	mustNewValidator("PK", 24, "PK\\d{2}[A-Z]{4}[A-Z0-9]{16}"),          // Pakistan
+	mustNewValidator("PL", 28, "PL\\d{26}"),                             // Poland
+	mustNewValidator("PS", 29, "PS\\d{2}[A-Z]{4}[A-Z0-9]{21}"),          // Palestine, State of
+	mustNewValidator("PT", 25, "PT\\d{23}"),                             // Portugal
+	mustNewValidator("QA", 29, "QA\\d{2}[A-Z]{4}[A-Z0-9]{21}"),          // Qatar
+	mustNewValidator("RO", 24, "RO\\d{2}[A-Z]{4}[A-Z0-9]{16}"),          // Romania
+	mustNewValidator("RS", 22, "RS\\d{20}"),                             // Serbia
+	mustNewValidator("RU", 33, "RU\\d{16}[A-Z0-9]{15}"),                 // Russia
+	mustNewValidator("SA", 24, "SA\\d{4}[A-Z0-9]{18}"),                  // Saudi Arabia
+	mustNewValidator("SC", 31, "SC\\d{2}[A-Z]{4}\\d{20}[A-Z]{3}"),       // Seychelles
+	mustNewValidator("SD", 18, "SD\\d{16}"),                             // Sudan
+	mustNewValidator("SE", 24, "SE\\d{22}"),                             // Sweden
+	mustNewValidator("SI", 19, "SI\\d{17}"),                             // Slovenia
+	mustNewValidator("SK", 24, "SK\\d{22}"),                             // Slovak Republic
+	mustNewValidator("SM", 27, "SM\\d{2}[A-Z]{1}\\d{10}[A-Z0-9]{12}"),   // San Marino
+	mustNewValidator("SO", 23, "SO\\d{21}"),                             // Somalia
+	mustNewValidator("ST", 25, "ST\\d{23}"),                             // Sao Tome and Principe
+	mustNewValidator("SV", 28, "SV\\d{2}[A-Z]{4}\\d{20}"),               // El Salvador
+	mustNewValidator("TL", 23, "TL\\d{21}"),                             // Timor-Leste
+	mustNewValidator("TN", 24, "TN\\d{22}"),                             // Tunisia
+	mustNewValidator("TR", 26, "TR\\d{8}[A-Z0-9]{16}"),                  // Türkiye
+	mustNewValidator("UA", 29, "UA\\d{8}[A-Z0-9]{19}"),                  // Ukraine
+	mustNewValidator("VA", 22, "VA\\d{20}"),                             // Vatican City State
+	mustNewValidator("VG", 24, "VG\\d{2}[A-Z]{4}\\d{16}"),               // Virgin Islands, British
+	mustNewValidator("XK", 20, "XK\\d{18}"),                             // Republic of Kosovo
+	mustNewValidator("YE", 30, "YE\\d{2}[A-Z]{4}\\d{4}[A-Z0-9]{18}"),    // Yemen
+}
+
+/**
+ * IBAN holds the parts of a valid IBAN.
+ */
+type IBAN struct {
+	CountryCode string
+	CheckDigits string
+	BBAN        string
+}
+
+/**
+ * Returns the IBAN in its electronic format, without spaces.
+ * @return the IBAN
+ */
+func (i IBAN) String() string {
+	return i.CountryCode + i.CheckDigits + i.BBAN
+}
+
+/**
+ * IBAN Validator.
+ *
+ * An IBANValidator holds a registry of the IBAN formats of each
+ * country: the length of its IBANs and a regular expression for their
+ * format. New returns a validator for the countries of the SWIFT IBAN
+ * registry; countries can be added, replaced or removed with
+ * SetValidator and RemoveValidator. The registry of the validator used
+ * by the package level functions can't be changed.
+ *
+ * IBANs are validated in the electronic format, in upper case, or in
+ * the print format with a space between groups of four characters. The
+ * check digits are validated with checkdigit.IBAN_CHECK_DIGIT.
+ *
+ * An IBANValidator is safe for concurrent use, including while its
+ * registry is updated.
+ */
+type IBANValidator struct {
+	mu               sync.RWMutex
+	formatValidators map[string]*Validator
+}
+
+/**
+ * Create a default IBAN validator, for the countries of the SWIFT IBAN
+ * registry known to this package.
+ * @return the validator
+ */
+func New() *IBANValidator {
+	return NewWithValidators(defaultFormats...)
+}
+
+/**
+ * Create an IBAN validator from the specified validators. If several
+ * have the same country code, the last one is used.
+ * @param validators the validators for each country
+ * @return the validator
+ */
+func NewWithValidators(validators ...*Validator) *IBANValidator {
+	v := &IBANValidator{formatValidators: make(map[string]*Validator, len(validators))}
+	for _, validator := range validators {
+		v.formatValidators[validator.countryCode] = validator
+	}
+	return v
+}
+
+var defaultValidator = New()
+
+/**
+ * Validate an IBAN Code using the default validator.
+ * @param code The value validation is being performed on
+ * @return true if the value is valid
+ */
+func IsValid(code string) bool {
+	return defaultValidator.IsValid(code)
+}
+
+/**
+ * Validate an IBAN Code.
+ * @param code The value validation is being performed on
+ * @return true if the value is valid
+ */
+func (v *IBANValidator) IsValid(code string) bool {
+	_, err := v.validate(code)
+	return err == nil
+}
+
+/**
+ * Validates an IBAN using the default validator.
+ * @param code The value validation is being performed on
+ * @return nil if the IBAN is valid, otherwise a *ValidationError.
+ */
+func Validate(code string) error {
+	return defaultValidator.Validate(code)
+}
+
+/**
+ * Validates an IBAN, reporting the first problem found. Leading and
+ * trailing whitespace and single spaces between the four character
+ * groups of the print format are ignored; any other space is reported
+ * as ErrInvalidSpace. Offsets in the returned error are relative to
+ * code as given.
+ * @param code The value validation is being performed on
+ * @return nil if the IBAN is valid, otherwise a *ValidationError.
+ */
+func (v *IBANValidator) Validate(code string) error {
+	if _, err := v.validate(code); err != nil {
+		return err
+	}
+	return nil
+}
+
+/**
+ * Parses an IBAN using the default validator.
+ * @param code The value validation is being performed on
+ * @return the parts of the IBAN, and nil if it is valid, otherwise a
+ * *ValidationError.
+ */
+func Parse(code string) (IBAN, error) {
+	return defaultValidator.Parse(code)
+}
+
+/**
+ * Validates an IBAN, see Validate, and returns its country code, check
+ * digits and BBAN (Basic Bank Account Number).
+ * @param code The value validation is being performed on
+ * @return the parts of the IBAN, and nil if it is valid, otherwise a
+ * *ValidationError.
+ */
+func (v *IBANValidator) Parse(code string) (IBAN, error) {
+	iban, err := v.validate(code)
+	if err != nil {
+		return IBAN{}, err
+	}
+	return IBAN{CountryCode: iban[:2], CheckDigits: iban[2:4], BBAN: iban[4:]}, nil
+}
+
+/**
+ * Formats an IBAN in the print format: groups of four characters
+ * separated by a space, as in GB29 NWBK 6016 1331 9268 19. Spaces
+ * already present are removed first. The IBAN isn't validated.
+ * @param code the IBAN, in the electronic or print format
+ * @return the IBAN in the print format
+ */
+func Format(code string) string {
+	code = strings.Replace(strings.TrimSpace(code), " ", "", -1)
+	var b strings.Builder
+	b.Grow(len(code) + len(code)/4)
+	for i := 0; i < len(code); i += 4 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		end := i + 4
+		if end > len(code) {
+			end = len(code)
+		}
+		b.WriteString(code[i:end])
+	}
+	return b.String()
+}
+
+/**
+ * Does the default validator have a validator for the country code?
+ * @param code the code to check (only the first two characters are used)
+ * @return true if there is a validator
+ */
+func HasValidator(code string) bool {
+	return defaultValidator.HasValidator(code)
+}
+
+/**
+ * Does the class have the required validator?
+ * @param code the code to check (only the first two characters are used)
+ * @return true if there is a validator
+ */
+func (v *IBANValidator) HasValidator(code string) bool {
+	return v.GetValidator(code) != nil
+}
+
+/**
+ * Gets the Validator for the country code of the default validator.
+ * @param code the code to check (only the first two characters are used)
+ * @return the validator or nil if there is not one registered
+ */
+func GetValidator(code string) *Validator {
+	return defaultValidator.GetValidator(code)
+}
+
+/**
+ * Get the Validator for a given IBAN.
+ * @param code a string starting with the ISO country code (e.g. an IBAN)
+ * @return the validator or nil if there is not one registered.
+ */
+func (v *IBANValidator) GetValidator(code string) *Validator {
+	if len(code) < 2 { // ensure we can extract the code
+		return nil
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.formatValidators[code[:2]]
+}
+
+/**
+ * Gets a copy of the validators, sorted by country code.
+ * @return a copy of the validator array
+ */
+func (v *IBANValidator) GetValidators() []*Validator {
+	v.mu.RLock()
+	validators := make([]*Validator, 0, len(v.formatValidators))
+	for _, validator := range v.formatValidators {
+		validators = append(validators, validator)
+	}
+	v.mu.RUnlock()
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i].countryCode < validators[j].countryCode
+	})
+	return validators
+}
+
+/**
+ * Installs a validator, such as one for a country added to the SEPA
+ * (Single Euro Payments Area). Will replace any existing entry which
+ * has a matching countryCode. Validators of other IBANValidators,
+ * including the one used by the package level functions, are not
+ * affected.
+ * @param validator the instance to install, which must have been
+ * created by NewValidator
+ * @return the previous Validator, or nil if there was none, and nil,
+ * or ErrInvalidValidator if validator is nil or was not created by
+ * NewValidator
+ */
+func (v *IBANValidator) SetValidator(validator *Validator) (*Validator, error) {
+	if validator == nil || validator.regex == nil {
+		return nil, ErrInvalidValidator
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	previous := v.formatValidators[validator.countryCode]
+	v.formatValidators[validator.countryCode] = validator
+	return previous, nil
+}
+
+/**
+ * Removes the validator of a country, so its IBANs are no longer
+ * valid.
+ * @param countryCode the country code
+ * @return the removed Validator, or nil if there was none
+ */
+func (v *IBANValidator) RemoveValidator(countryCode string) *Validator {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	previous := v.formatValidators[countryCode]
+	delete(v.formatValidators, countryCode)
+	return previous
+}
+
+/*
+ * validate returns code in the electronic format if it is a valid
+ * IBAN, otherwise the reason it isn't. Offsets in the error are mapped
+ * back to code as given.
+ */
+func (v *IBANValidator) validate(code string) (string, *ValidationError) {
+	lead := len(code) - len(strings.TrimLeftFunc(code, unicode.IsSpace))
+	trimmed := strings.TrimSpace(code)
+	if trimmed == "" {
+		return "", &ValidationError{Err: ErrEmpty}
+	}
+
+	// spaces may only end a group of four characters, as in
+	// GB29 NWBK 6016 1331 9268 19
+	n := 0
+	for i := 0; i < len(trimmed); i++ {
+		switch c := trimmed[i]; {
+		case c == ' ':
+			if n%4 != 0 || trimmed[i-1] == ' ' {
+				return "", &ValidationError{Err: ErrInvalidSpace, Segment: " ", Offset: lead + i}
+			}
+		case !isUpper(c) && !isDigit(c):
+			_, size := utf8.DecodeRuneInString(trimmed[i:])
+			return "", &ValidationError{Err: ErrInvalidCharacter, Segment: trimmed[i : i+size], Offset: lead + i}
+		default:
+			n++
+		}
+	}
+	iban := trimmed
+	if n != len(trimmed) {
+		iban = strings.Replace(trimmed, " ", "", -1)
+	}
+
+	fail := func(err error, start, end int) (string, *ValidationError) {
+		segment, offset := printSpan(trimmed, start, end)
+		return "", &ValidationError{Err: err, Segment: segment, Offset: lead + offset}
+	}
+
+	formatValidator := v.GetValidator(iban)
+	if formatValidator == nil {
+		end := 2
+		if len(iban) < end {
+			end = len(iban)
+		}
+		return fail(ErrUnknownCountry, 0, end)
+	}
+	if len(iban) != formatValidator.lengthOfIBAN {
+		return fail(ErrInvalidLength, 0, len(iban))
+	}
+	if !isDigit(iban[2]) || !isDigit(iban[3]) {
+		return fail(ErrInvalidFormat, 2, 4)
+	}
+	if !formatValidator.regex.IsValid(iban) {
+		return fail(ErrInvalidFormat, 4, len(iban))
+	}
+	if !checkdigit.IBAN_CHECK_DIGIT.IsValid(iban) {
+		return fail(ErrInvalidCheckDigit, 2, 4)
+	}
+	return iban, nil
+}
+
+/*
+ * printSpan returns the part of s, which may contain spaces, that
+ * holds the characters start to end of s without spaces, and its
+ * offset in s.
+ */
+func printSpan(s string, start, end int) (string, int) {
+	from, n := -1, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' {
+			continue
+		}
+		if n == start {
+			from = i
+		}
+		n++
+		if n == end {
+			return s[from : i+1], from
+		}
+	}
+	return s[from:], from
+}
+
+func isUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
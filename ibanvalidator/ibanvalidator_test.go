@@ -0,0 +1,402 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/IBANValidatorTest.java?view=log
+ */
+package ibanvalidator
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// The examples of the SWIFT IBAN registry
+var validIBANFormat = []string{
+	"AD1200012030200359100100",
+	"AE070331234567890123456",
+	"AL47212110090000000235698741",
+	"AT611904300234573201",
+	"AZ21NABZ00000000137010001944",
+	"BA391290079401028494",
+	"BE68539007547034",
+	"BG80BNBG96611020345678",
+	"BH67BMAG00001299123456",
+	"BI4210000100010000332045181",
+	"BR1800360305000010009795493C1",
+	"BY13NBRB3600900000002Z00AB00",
+	"CH9300762011623852957",
+	"CR05015202001026284066",
+	"CY17002001280000001200527600",
+	"CZ6508000000192000145399",
+	"DE89370400440532013000",
+	"DJ2100010000000154000100186",
+	"DK5000400440116243",
+	"DO28BAGR00000001212453611324",
+	"EE382200221020145685",
+	"EG380019000500000000263180002",
+	"ES9121000418450200051332",
+	"FI2112345600000785",
+	"FK88SC123456789012",
+	"FO6264600001631634",
+	"FR1420041010050500013M02606",
+	"GB29NWBK60161331926819",
+	"GE29NB0000000101904917",
+	"GI75NWBK000000007099453",
+	"GL8964710001000206",
+	"GR1601101250000000012300695",
+	"GT82TRAJ01020000001210029690",
+	"HN88CABF00000000000250005469",
+	"HR1210010051863000160",
+	"HU42117730161111101800000000",
+	"IE29AIBK93115212345678",
+	"IL620108000000099999999",
+	"IQ98NBIQ850123456789012",
+	"IS140159260076545510730339",
+	"IT60X0542811101000000123456",
+	"JO94CBJO0010000000000131000302",
+	"KW81CBKU0000000000001234560101",
+	"KZ86125KZT5004100100",
+	"LB62099900000001001901229114",
+	"LC55HEMM000100010012001200023015",
+	"LI21088100002324013AA",
+	"LT121000011101001000",
+	"LU280019400644750000",
+	"LV80BANK0000435195001",
+	"LY83002048000020100120361",
+	"MC5811222000010123456789030",
+	"MD24AG000225100013104168",
+	"ME25505000012345678951",
+	"MK07250120000058984",
+	"MN121234123456789123",
+	"MR1300020001010000123456753",
+	"MT84MALT011000012345MTLCAST001S",
+	"MU17BOMM0101101030300200000MUR",
+	"NI45BAPR00000013000003558124",
+	"NL91ABNA0417164300",
+	"NO9386011117947",
+	"OM810180000001299123456",
+	"PK36SCBL0000001123456702",
+	"PL61109010140000071219812874",
+	"PS92PALS000000000400123456702",
+	"PT50000201231234567890154",
+	"QA58DOHB00001234567890ABCDEFG",
+	"RO49AAAA1B31007593840000",
+	"RS35260005601001611379",
+	"RU0204452560040702810412345678901",
+	"SA0380000000608010167519",
+	"SC18SSCB11010000000000001497USD",
+	"SD2129010501234001",
+	"SE4550000000058398257466",
+	"SI56263300012039086",
+	"SK3112000000198742637541",
+	"SM86U0322509800000000270100",
+	"SO211000001001000100141",
+	"ST68000100010051845310112",
+	"SV62CENR00000000000000700025",
+	"TL380080012345678910157",
+	"TN5910006035183598478831",
+	"TR330006100519786457841326",
+	"UA213223130000026007233566001",
+	"VA59001123000012345678",
+	"VG96VPVG0000012345678901",
+	"XK051212012345678906",
+	"YE15CBYE0001018861234567891234",
+}
+
+var invalidIBANFormat = []string{
+	"",                                 // empty
+	"   ",                              // empty
+	"A",                                // too short
+	"AB",                               // too short
+	"FR1420041010050500013m02606",      // lowercase version
+	"MT84MALT011000012345mtlcast001s",  // lowercase version
+	"LI21088100002324013aa",            // lowercase version
+	"QA58DOHB00001234567890abcdefg",    // lowercase version
+	"RO49AAAA1b31007593840000",         // lowercase version
+	"LC62HEMM000100010012001200023015", // wrong check digits
+	"BY00NBRB3600000000000Z00AB00",     // Wrong checkdigit
+	"ST68000200010192194210112",        // ditto
+	"SV62CENR0000000000000700025",      // ditto
+	"GB29NWBK60161331926818",           // wrong check digits
+	"GB29NWBK6016133192681",            // too short
+	"GB29NWBK601613319268190",          // too long
+	"GB2XNWBK60161331926819",           // check digits not numeric
+	"GB291WBK60161331926819",           // bank code not alphabetic
+	"ZZ29NWBK60161331926819",           // unknown country
+	"GB29-NWBK-6016-1331-9268-19",      // separators
+}
+
+func TestValid(t *testing.T) {
+	for _, code := range validIBANFormat {
+		if !IsValid(code) {
+			t.Errorf("expected valid IBAN: %s", code)
+		}
+		if !HasValidator(code) {
+			t.Errorf("expected a validator for: %s", code)
+		}
+	}
+}
+
+func TestInValid(t *testing.T) {
+	for _, code := range invalidIBANFormat {
+		if IsValid(code) {
+			t.Errorf("expected invalid IBAN: %s", code)
+		}
+	}
+}
+
+/**
+ * Test IBANs in the print format and with surrounding whitespace.
+ */
+func TestPrintFormat(t *testing.T) {
+	for _, code := range validIBANFormat {
+		if !IsValid(Format(code)) {
+			t.Errorf("expected valid IBAN: %s", Format(code))
+		}
+	}
+
+	tests := []struct {
+		code   string
+		format string
+	}{
+		{"GB29NWBK60161331926819", "GB29 NWBK 6016 1331 9268 19"},
+		{"GB29 NWBK 6016 1331 9268 19", "GB29 NWBK 6016 1331 9268 19"},
+		{" NL91ABNA0417164300\n", "NL91 ABNA 0417 1643 00"},
+		{"NO9386011117947", "NO93 8601 1117 947"},
+		{"BE68539007547034", "BE68 5390 0754 7034"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.code); got != tt.format {
+			t.Errorf("Format(%q): got %q, want %q", tt.code, got, tt.format)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	iban, err := Parse("GB29 NWBK 6016 1331 9268 19")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if iban.CountryCode != "GB" || iban.CheckDigits != "29" || iban.BBAN != "NWBK60161331926819" {
+		t.Errorf("Parse: got %+v", iban)
+	}
+	if got := iban.String(); got != "GB29NWBK60161331926819" {
+		t.Errorf("String: got %q", got)
+	}
+
+	if iban, err := Parse("GB29NWBK60161331926818"); err == nil || iban != (IBAN{}) {
+		t.Errorf("Parse: expected error, got %+v", iban)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		code    string
+		err     error
+		segment string
+		offset  int
+	}{
+		{"GB29NWBK60161331926819", nil, "", 0},
+		{"", ErrEmpty, "", 0},
+		{"  ", ErrEmpty, "", 0},
+		{"gb29NWBK60161331926819", ErrInvalidCharacter, "g", 0},
+		{"GB29-NWBK", ErrInvalidCharacter, "-", 4},
+		{"GB29 NWBK 6016 1331 9268 1é", ErrInvalidCharacter, "é", 26},
+		{"GB 8 2WEST12345698765432", ErrInvalidSpace, " ", 2},
+		{"GB82 WEST1 2345698765432", ErrInvalidSpace, " ", 10},
+		{"GB82  WEST 1234 5698 7654 32", ErrInvalidSpace, " ", 5},
+		{" GB82 WEST 1234 5698 7654 32 ", nil, "", 0},
+		{"ZZ29NWBK60161331926819", ErrUnknownCountry, "ZZ", 0},
+		{"G", ErrUnknownCountry, "G", 0},
+		{"GB29NWBK6016133192681", ErrInvalidLength, "GB29NWBK6016133192681", 0},
+		{" GB29 NWBK 6016 1331 9268 1", ErrInvalidLength, "GB29 NWBK 6016 1331 9268 1", 1},
+		{"GB2XNWBK60161331926819", ErrInvalidFormat, "2X", 2},
+		{"GB291WBK60161331926819", ErrInvalidFormat, "1WBK60161331926819", 4},
+		{"GB29 1WBK 6016 1331 9268 19", ErrInvalidFormat, "1WBK 6016 1331 9268 19", 5},
+		{"GB29NWBK60161331926818", ErrInvalidCheckDigit, "29", 2},
+		{"GB2 9NWBK60161331926818", ErrInvalidSpace, " ", 3},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.code)
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q): unexpected error %v", tt.code, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Validate(%q): expected *ValidationError, got %v", tt.code, err)
+			continue
+		}
+		if verr.Err != tt.err || verr.Segment != tt.segment || verr.Offset != tt.offset {
+			t.Errorf("Validate(%q): got %v, %q, %d, want %v, %q, %d",
+				tt.code, verr.Err, verr.Segment, verr.Offset, tt.err, tt.segment, tt.offset)
+		}
+	}
+}
+
+func TestNewValidator(t *testing.T) {
+	invalid := []struct {
+		countryCode string
+		length      int
+		format      string
+	}{
+		{"GBX", 22, "GBX\\d{19}"},
+		{"gb", 22, "gb\\d{20}"},
+		{"G1", 22, "G1\\d{20}"},
+		{"GB", 7, "GB\\d{5}"},
+		{"GB", 35, "GB\\d{33}"},
+		{"GB", 22, "\\d{22}"},
+		{"GB", 22, "GB(\\d{20}"},
+		{"DE", 22, "DE)|(.*"},
+	}
+	for _, tt := range invalid {
+		if _, err := NewValidator(tt.countryCode, tt.length, tt.format); err == nil {
+			t.Errorf("NewValidator(%q, %d, %q): expected error", tt.countryCode, tt.length, tt.format)
+		}
+	}
+
+	v, err := NewValidator("XY", 14, "XY\\d{12}")
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+	if v.CountryCode() != "XY" || v.LengthOfIBAN() != 14 || v.Format() != "XY\\d{12}" {
+		t.Errorf("got %q, %d, %q", v.CountryCode(), v.LengthOfIBAN(), v.Format())
+	}
+}
+
+/**
+ * Test the registry can be changed without affecting other validators.
+ */
+func TestSetValidator(t *testing.T) {
+	v := New()
+	if got := len(v.GetValidators()); got != len(validIBANFormat) {
+		t.Errorf("expected %d validators, got %d", len(validIBANFormat), got)
+	}
+
+	const newIBAN = "XY331234567890"
+	if v.IsValid(newIBAN) || v.HasValidator(newIBAN) {
+		t.Errorf("expected no validator for XY")
+	}
+	if previous, err := v.SetValidator(mustNewValidator("XY", 14, "XY\\d{12}")); previous != nil || err != nil {
+		t.Errorf("expected no previous validator, got %v, %v", previous, err)
+	}
+	if !v.IsValid(newIBAN) || !v.IsValid("XY33 1234 5678 90") {
+		t.Errorf("expected valid IBAN: %s", newIBAN)
+	}
+	if IsValid(newIBAN) || New().IsValid(newIBAN) {
+		t.Errorf("expected other validators to be unaffected")
+	}
+
+	// Replace, then remove the validator of a country
+	gb := v.GetValidator("GB")
+	if previous, err := v.SetValidator(mustNewValidator("GB", 22, "GB\\d{20}")); previous != gb || err != nil {
+		t.Errorf("expected the previous GB validator, got %v, %v", previous, err)
+	}
+	if v.IsValid("GB29NWBK60161331926819") {
+		t.Errorf("expected replaced GB format to be used")
+	}
+	if previous := v.RemoveValidator("GB"); previous == nil || previous.Format() != "GB\\d{20}" {
+		t.Errorf("expected the removed GB validator")
+	}
+	if err := v.Validate("GB29NWBK60161331926819"); !errors.Is(err, ErrUnknownCountry) {
+		t.Errorf("expected ErrUnknownCountry, got %v", err)
+	}
+	if !IsValid("GB29NWBK60161331926819") {
+		t.Errorf("expected default validator to be unaffected")
+	}
+
+	// GetValidators is sorted
+	validators := v.GetValidators()
+	for i := 1; i < len(validators); i++ {
+		if validators[i-1].CountryCode() >= validators[i].CountryCode() {
+			t.Errorf("expected validators sorted by country code")
+			break
+		}
+	}
+
+	// Only validators created by NewValidator can be installed
+	count := len(v.GetValidators())
+	for _, validator := range []*Validator{nil, {}, {countryCode: "XY", lengthOfIBAN: 14}} {
+		if previous, err := v.SetValidator(validator); previous != nil || !errors.Is(err, ErrInvalidValidator) {
+			t.Errorf("SetValidator(%v): got %v, %v", validator, previous, err)
+		}
+	}
+	if len(v.GetValidators()) != count || !v.IsValid(newIBAN) {
+		t.Errorf("expected rejected validators not to be installed")
+	}
+
+	custom := NewWithValidators(mustNewValidator("XY", 14, "XY\\d{12}"))
+	if !custom.IsValid(newIBAN) || custom.IsValid("GB29NWBK60161331926819") {
+		t.Errorf("expected only XY to be valid")
+	}
+}
+
+func TestConcurrentSetValidator(t *testing.T) {
+	v := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v.SetValidator(mustNewValidator("XY", 14, "XY\\d{12}"))
+				v.RemoveValidator("XY")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if !v.IsValid("GB29NWBK60161331926819") {
+					t.Errorf("expected valid IBAN")
+				}
+				v.GetValidators()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+/**
+ * Test the default formats agree with their lengths and examples,
+ * including countries added to the registry after 2017.
+ */
+func TestDefaultFormats(t *testing.T) {
+	for _, v := range defaultFormats {
+		example := ""
+		for _, code := range validIBANFormat {
+			if strings.HasPrefix(code, v.CountryCode()) {
+				example = code
+			}
+		}
+		if len(example) != v.LengthOfIBAN() {
+			t.Errorf("%s: example %q has length %d, want %d", v.CountryCode(), example, len(example), v.LengthOfIBAN())
+		}
+		if !IsValid(example) {
+			t.Errorf("%s: expected valid example %q", v.CountryCode(), example)
+		}
+	}
+
+	for _, code := range []string{"VA59001123000012345678", "LY83002048000020100120361"} {
+		if !IsValid(code) {
+			t.Errorf("expected valid IBAN: %s", code)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/InetAddressValidator.java?view=log
+ */
+package inetaddressvalidator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	IPV4_REGEX = "^(\\d{1,3})\\.(\\d{1,3})\\.(\\d{1,3})\\.(\\d{1,3})$"
+
+	// Zone IDs may contain anything but whitespace, '/' and '%' (RFC 4007, RFC 6874)
+	ZONE_ID_REGEX = "^[^\\s/%]+$"
+	PREFIX_REGEX  = "^\\d{1,3}$"
+
+	IPV4_MAX_OCTET_VALUE          = 255
+	IPV6_MAX_HEX_GROUPS           = 8
+	IPV6_MAX_HEX_DIGITS_PER_GROUP = 4
+	IPV4_MAX_PREFIX_LENGTH        = 32
+	IPV6_MAX_PREFIX_LENGTH        = 128
+	BASE_16                       = 16
+)
+
+var (
+	ipv4Regex   = regexp.MustCompile(IPV4_REGEX)
+	zoneIdRegex = regexp.MustCompile(ZONE_ID_REGEX)
+	prefixRegex = regexp.MustCompile(PREFIX_REGEX)
+)
+
+/**
+ * Checks if the specified string is a valid IPv4 or IPv6 address.
+ * Zone IDs and prefix lengths are not accepted; see
+ * IsValidInet6AddressWithZone and IsValidCidr.
+ * @param inetAddress the string to validate
+ * @return true if the string validates as an IP address
+ */
+func IsValid(inetAddress string) bool {
+	return IsValidInet4Address(inetAddress) || IsValidInet6Address(inetAddress)
+}
+
+/**
+ * Validates an IPv4 address. Returns true if valid.
+ * @param inet4Address the IPv4 address to validate
+ * @return true if the argument contains a valid IPv4 address
+ */
+func IsValidInet4Address(inet4Address string) bool {
+	// verify that address conforms to generic IPv4 format
+	if !ipv4Regex.MatchString(inet4Address) {
+		return false
+	}
+
+	// verify that address subgroups are legal; the regex guarantees
+	// four dot separated runs of 1-3 digits
+	for rest := inet4Address; rest != ""; {
+		ipSegment := rest
+		if dot := strings.IndexByte(rest, '.'); dot >= 0 {
+			ipSegment, rest = rest[:dot], rest[dot+1:]
+		} else {
+			rest = ""
+		}
+
+		iIpSegment, err := strconv.Atoi(ipSegment)
+		if err != nil {
+			return false
+		}
+
+		if iIpSegment > IPV4_MAX_OCTET_VALUE {
+			return false
+		}
+
+		// leading zeros are ambiguous (octal in some resolvers)
+		if len(ipSegment) > 1 && strings.HasPrefix(ipSegment, "0") {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * Validates an IPv6 address as described in RFC 4291, including the
+ * "::" zero compression and an IPv4 address in the last 32 bits.
+ * Returns true if valid.
+ * @param inet6Address the IPv6 address to validate
+ * @return true if the argument contains a valid IPv6 address
+ */
+func IsValidInet6Address(inet6Address string) bool {
+	containsCompressedZeroes := strings.Contains(inet6Address, "::")
+	if containsCompressedZeroes && strings.Index(inet6Address, "::") != strings.LastIndex(inet6Address, "::") {
+		return false
+	}
+	if (strings.HasPrefix(inet6Address, ":") && !strings.HasPrefix(inet6Address, "::")) ||
+		(strings.HasSuffix(inet6Address, ":") && !strings.HasSuffix(inet6Address, "::")) {
+		return false
+	}
+	octets := strings.Split(inet6Address, ":")
+	if containsCompressedZeroes {
+		// a leading or trailing "::" splits into one empty group too many
+		if strings.HasSuffix(inet6Address, "::") {
+			octets = octets[:len(octets)-1]
+		}
+		if strings.HasPrefix(inet6Address, "::") {
+			octets = octets[1:]
+		}
+	}
+	if len(octets) > IPV6_MAX_HEX_GROUPS {
+		return false
+	}
+	validOctets := 0
+	emptyOctets := 0
+	for index, octet := range octets {
+		if octet == "" {
+			emptyOctets++
+			if emptyOctets > 1 {
+				return false
+			}
+		} else {
+			emptyOctets = 0
+			// Is last chunk an IPv4 address?
+			if index == len(octets)-1 && strings.Contains(octet, ".") {
+				if !IsValidInet4Address(octet) {
+					return false
+				}
+				validOctets += 2
+				continue
+			}
+			if len(octet) > IPV6_MAX_HEX_DIGITS_PER_GROUP {
+				return false
+			}
+			if _, err := strconv.ParseUint(octet, BASE_16, 16); err != nil {
+				return false
+			}
+		}
+		validOctets++
+	}
+	if validOctets > IPV6_MAX_HEX_GROUPS || (validOctets < IPV6_MAX_HEX_GROUPS && !containsCompressedZeroes) {
+		return false
+	}
+	return true
+}
+
+/**
+ * Validates an IPv6 address optionally followed by a '%' and a zone ID,
+ * e.g. fe80::1%eth0, as described in RFC 4007. Returns true if valid.
+ * @param inet6Address the IPv6 address to validate
+ * @return true if the argument contains a valid IPv6 address
+ */
+func IsValidInet6AddressWithZone(inet6Address string) bool {
+	parts := strings.Split(inet6Address, "%")
+	if len(parts) > 2 {
+		return false
+	} else if len(parts) == 2 && !zoneIdRegex.MatchString(parts[1]) {
+		// invalid zone ID
+		return false
+	}
+	return IsValidInet6Address(parts[0])
+}
+
+/**
+ * Validates an IPv4 or IPv6 address followed by a '/' and a prefix
+ * length in CIDR notation, e.g. 192.168.0.0/16 or 2001:db8::/32.
+ * Returns true if valid.
+ * @param cidr the address block to validate
+ * @return true if the argument contains a valid address and prefix length
+ */
+func IsValidCidr(cidr string) bool {
+	parts := strings.Split(cidr, "/")
+	if len(parts) != 2 || !prefixRegex.MatchString(parts[1]) {
+		return false
+	}
+	prefixLength, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	if IsValidInet4Address(parts[0]) {
+		return prefixLength <= IPV4_MAX_PREFIX_LENGTH
+	}
+	if IsValidInet6Address(parts[0]) {
+		return prefixLength <= IPV6_MAX_PREFIX_LENGTH
+	}
+	return false
+}
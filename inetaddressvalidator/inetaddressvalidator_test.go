@@ -0,0 +1,208 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/InetAddressValidatorTest.java?view=log
+ */
+package inetaddressvalidator
+
+import (
+	"testing"
+)
+
+/**
+ * Test IPs that point to real, well-known hosts (without actually looking them up).
+ */
+func TestInetAddressesFromTheWild(t *testing.T) {
+	validAddresses := []string{
+		`140.211.11.130`,       // www.apache.org IP should be valid
+		`72.14.253.103`,        // www.l.google.com IP should be valid
+		`199.232.41.5`,         // fsf.org IP should be valid
+		`216.35.123.87`,        // appscs.ign.com IP should be valid
+		`2001:db8::1`,          // documentation IPv6 should be valid
+		`2606:4700:4700::1111`, // one.one.one.one IPv6 should be valid
+	}
+	for _, address := range validAddresses {
+		if !IsValid(address) {
+			t.Errorf("expected valid address: %s", address)
+		}
+	}
+}
+
+/**
+ * Test valid and invalid IPs from each address class.
+ */
+func TestInetAddressesByClass(t *testing.T) {
+	validAddresses := []string{
+		`24.25.231.12`,    // class A IP should be valid
+		`135.14.44.12`,    // class B IP should be valid
+		`213.25.224.32`,   // class C IP should be valid
+		`226.14.44.12`,    // class D (multicast) IP should be valid
+		`240.14.44.12`,    // class E IP should be valid
+		`0.0.0.0`,         // all-zero IP should be valid
+		`255.255.255.255`, // all-255 IP should be valid
+	}
+	for _, address := range validAddresses {
+		if !IsValidInet4Address(address) {
+			t.Errorf("expected valid IPv4 address: %s", address)
+		}
+	}
+
+	invalidAddresses := []string{
+		`2.41.32.324`,     // illegal class A IP should be invalid
+		`154.123.441.123`, // illegal class B IP should be invalid
+		`201.543.23.11`,   // illegal class C IP should be invalid
+		`231.54.11.987`,   // illegal class D IP should be invalid
+		`266.123.44.12`,   // illegal class E IP should be invalid
+		`1.2.3.4.5`,       // IP with too many octets should be invalid
+		`1.2.3`,           // IP with too few octets should be invalid
+		`1.2.3.`,          // IP with trailing dot should be invalid
+		`1.2.3.04`,        // IP with leading zero should be invalid
+		`a.b.c.d`,         // IP with letters should be invalid
+		``,                // empty string should be invalid
+		` 1.2.3.4`,        // IP with whitespace should be invalid
+	}
+	for _, address := range invalidAddresses {
+		if IsValidInet4Address(address) {
+			t.Errorf("expected invalid IPv4 address: %s", address)
+		}
+	}
+}
+
+/**
+ * Inet6Address may also contain a scope id, and may be written in
+ * compressed form. Based on the cases in RFC 4291 section 2.2.
+ */
+func TestIPv6(t *testing.T) {
+	validAddresses := []string{
+		`::`,
+		`::1`,
+		`1::`,
+		`2001:db8::`,
+		`fe80::217:f2ff:fe07:ed62`,
+		`2001:0db8:85a3:0000:0000:8a2e:0370:7334`,
+		`2001:DB8:85A3:0:0:8A2E:370:7334`,
+		`1:2:3:4:5:6:7::`,
+		`::2:3:4:5:6:7:8`,
+		`::ffff:192.0.2.128`,
+		`::192.0.2.128`,
+		`1:2:3:4:5:6:1.2.3.4`,
+		`1::5:1.2.3.4`,
+	}
+	for _, address := range validAddresses {
+		if !IsValidInet6Address(address) {
+			t.Errorf("expected valid IPv6 address: %s", address)
+		}
+		if !IsValid(address) {
+			t.Errorf("expected valid address: %s", address)
+		}
+	}
+
+	invalidAddresses := []string{
+		``,
+		`:`,
+		`:::`,
+		`1:2:3:4:5:6:7`,
+		`1:2:3:4:5:6:7:8:9`,
+		`1:2:3:4:5:6:7:8::`,
+		`::1:2:3:4:5:6:7:8`,
+		`1::2::3`,
+		`:1::2`,
+		`1::2:`,
+		`12345::`,
+		`g::1`,
+		`1:2:3:4:5:6:7:1.2.3.4`,
+		`::256.1.1.1`,
+		`::1.2.3.4:1`,
+		`1.2.3.4::`,
+		`fe80::1%eth0`,
+		`2001:db8::/32`,
+	}
+	for _, address := range invalidAddresses {
+		if IsValidInet6Address(address) {
+			t.Errorf("expected invalid IPv6 address: %s", address)
+		}
+	}
+}
+
+func TestIPv6WithZone(t *testing.T) {
+	validAddresses := []string{
+		`fe80::1%eth0`,
+		`fe80::1%1`,
+		`fe80::217:f2ff:fe07:ed62%en0`,
+		`fe80::1`, // the zone ID is optional
+	}
+	for _, address := range validAddresses {
+		if !IsValidInet6AddressWithZone(address) {
+			t.Errorf("expected valid IPv6 address: %s", address)
+		}
+	}
+
+	invalidAddresses := []string{
+		`fe80::1%`,
+		`fe80::1%eth 0`,
+		`fe80::1%eth0%1`,
+		`fe80::1%eth/0`,
+		`fe80:::1%eth0`,
+		`1.2.3.4%eth0`,
+	}
+	for _, address := range invalidAddresses {
+		if IsValidInet6AddressWithZone(address) {
+			t.Errorf("expected invalid IPv6 address: %s", address)
+		}
+	}
+}
+
+func TestCidr(t *testing.T) {
+	validBlocks := []string{
+		`192.168.0.0/16`,
+		`10.0.0.1/32`,
+		`0.0.0.0/0`,
+		`2001:db8::/32`,
+		`::/0`,
+		`::1/128`,
+	}
+	for _, block := range validBlocks {
+		if !IsValidCidr(block) {
+			t.Errorf("expected valid CIDR block: %s", block)
+		}
+	}
+
+	invalidBlocks := []string{
+		`192.168.0.0`,
+		`192.168.0.0/`,
+		`192.168.0.0/33`,
+		`192.168.0.0/-1`,
+		`192.168.0.0/1/2`,
+		`192.168.0.0/a`,
+		`256.168.0.0/16`,
+		`2001:db8::/129`,
+		`2001:db8::/1000`,
+		`2001:db8::1%eth0/64`,
+		`/32`,
+	}
+	for _, block := range invalidBlocks {
+		if IsValidCidr(block) {
+			t.Errorf("expected invalid CIDR block: %s", block)
+		}
+	}
+}
+
+func BenchmarkIsValidInet4Address(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValidInet4Address(`216.109.118.76`)
+	}
+}
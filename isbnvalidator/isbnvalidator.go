@@ -0,0 +1,374 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/ISBNValidator.java?view=log
+ */
+package isbnvalidator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dsparling/go-commons-validator/checkdigit"
+	"github.com/dsparling/go-commons-validator/regexvalidator"
+)
+
+const (
+	ISBN_10_LEN = 10
+	ISBN_13_LEN = 13
+
+	SEP       = "(?:\\-|\\s)"
+	GROUP     = "(\\d{1,5})"
+	PUBLISHER = "(\\d{1,7})"
+	TITLE     = "(\\d{1,6})"
+
+	/**
+	 * ISBN-10 consists of 4 groups of numbers separated by either dashes (-)
+	 * or spaces.  The first group is 1-5 characters, second 1-7, third 1-6,
+	 * and fourth is 1 digit or an X.
+	 */
+	ISBN10_REGEX = "^(?:(\\d{9}[0-9X])|(?:" + GROUP + SEP + PUBLISHER + SEP + TITLE + SEP + "([0-9X])))$"
+
+	/**
+	 * ISBN-13 consists of 5 groups of numbers separated by either dashes (-)
+	 * or spaces.  The first group is 978 or 979, the second group is
+	 * 1-5 characters, third 1-7, fourth 1-6, and fifth is 1 digit.
+	 */
+	ISBN13_REGEX = "^(978|979)(?:(\\d{10})|(?:" + SEP + GROUP + SEP + PUBLISHER + SEP + TITLE + SEP + "([0-9])))$"
+)
+
+var (
+	ErrEmpty             = errors.New("isbnvalidator: empty ISBN")
+	ErrInvalidFormat     = errors.New("isbnvalidator: invalid format")
+	ErrInvalidLength     = errors.New("isbnvalidator: invalid length")
+	ErrInvalidCheckDigit = errors.New("isbnvalidator: invalid check digit")
+)
+
+/**
+ * ValidationError reports why an ISBN failed validation. For
+ * ErrInvalidCheckDigit, Segment is the check character, the last of
+ * the ISBN. For ErrInvalidFormat and ErrInvalidLength it is the whole
+ * ISBN with its separators, without surrounding white space. Offset is
+ * the byte offset of Segment in the string passed to Validate. ErrEmpty
+ * has neither.
+ */
+type ValidationError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// codeValidator is CodeValidator in Commons Validator: a code is valid
+// if it matches regex, the groups matched have length characters and
+// their check digit is valid.
+type codeValidator struct {
+	regex      *regexvalidator.RegexValidator
+	length     int
+	checkDigit checkdigit.CheckDigit
+}
+
+var (
+	isbn10Validator = &codeValidator{
+		regex:      regexvalidator.MustNew([]string{ISBN10_REGEX}),
+		length:     ISBN_10_LEN,
+		checkDigit: checkdigit.ISBN10_CHECK_DIGIT,
+	}
+	isbn13Validator = &codeValidator{
+		regex:      regexvalidator.MustNew([]string{ISBN13_REGEX}),
+		length:     ISBN_13_LEN,
+		checkDigit: checkdigit.EAN13_CHECK_DIGIT,
+	}
+)
+
+// validate returns code without separators and surrounding
+// whitespace if it is valid, otherwise the reason it isn't.
+func (c *codeValidator) validate(code string) (string, *ValidationError) {
+	lead := len(code) - len(strings.TrimLeftFunc(code, unicode.IsSpace))
+	trimmed := strings.TrimSpace(code)
+	if trimmed == "" {
+		return "", &ValidationError{Err: ErrEmpty}
+	}
+	joined, ok := c.regex.ValidateAndJoin(trimmed)
+	if !ok {
+		return "", &ValidationError{Err: ErrInvalidFormat, Segment: trimmed, Offset: lead}
+	}
+	if len(joined) != c.length {
+		return "", &ValidationError{Err: ErrInvalidLength, Segment: trimmed, Offset: lead}
+	}
+	if !c.checkDigit.IsValid(joined) {
+		last := len(trimmed) - 1
+		return "", &ValidationError{Err: ErrInvalidCheckDigit, Segment: trimmed[last:], Offset: lead + last}
+	}
+	return joined, nil
+}
+
+/**
+ * ISBN-10 and ISBN-13 Code Validation.
+ *
+ * This validator validates the code is either a valid ISBN-10
+ * (using a CodeValidator with the ISBN10_CHECK_DIGIT)
+ * or a valid ISBN-13 code (using a CodeValidator with the
+ * EAN13_CHECK_DIGIT routine).
+ *
+ * The Normalize method returns the ISBN code with formatting
+ * characters removed if valid or an error if invalid.
+ *
+ * This validator also provides the facility to convert ISBN-10 codes
+ * to ISBN-13 if the Convert option is set (the default).
+ *
+ * ISBN-10 Numbers
+ *
+ * An ISBN-10 code should be 10 characters, consisting of 9 digits and
+ * a check character, which is a digit or X. There is also provision
+ * for the code to be split into four groups separated by dashes (-) or
+ * spaces:
+ *  - Group: 1-5 characters
+ *  - Publisher: 1-7 characters
+ *  - Title: 1-6 characters
+ *  - Check digit: 1 character
+ *
+ * ISBN-13 Numbers
+ *
+ * ISBN-13 Numbers are EAN-13 codes starting with 978 or 979. They may
+ * be split into five groups separated by dashes (-) or spaces, the
+ * first being the 978 or 979 prefix.
+ *
+ * Transition details
+ *
+ * Since 1 January 2007 the ISBN has been 13 digits, and ISBN-10 codes
+ * are converted by adding the 978 prefix and calculating a new EAN-13
+ * check digit.
+ *
+ * The zero value converts ISBN-10 codes, like the validator returned
+ * by New with no options. An ISBNValidator is safe for concurrent use.
+ */
+type ISBNValidator struct {
+	noConvert bool
+}
+
+/**
+ * Option configures an ISBNValidator created with New.
+ */
+type Option func(*ISBNValidator)
+
+/**
+ * Convert sets whether valid ISBN-10 codes are converted to ISBN-13
+ * by Normalize. The default is true.
+ * @param convert true if valid ISBN-10 codes should be converted
+ */
+func Convert(convert bool) Option {
+	return func(v *ISBNValidator) {
+		v.noConvert = !convert
+	}
+}
+
+/**
+ * Construct an ISBN validator, which converts ISBN-10 codes to ISBN-13
+ * unless the Convert option says otherwise.
+ * @param opts the options to apply
+ * @return the configured validator
+ */
+func New(opts ...Option) *ISBNValidator {
+	v := &ISBNValidator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+var defaultValidator = New()
+
+/**
+ * Check the code is either a valid ISBN-10 or ISBN-13 code.
+ * @param code The code to validate.
+ * @return true if a valid ISBN-10 or
+ * ISBN-13 code, otherwise false.
+ */
+func IsValid(code string) bool {
+	return defaultValidator.IsValid(code)
+}
+
+/**
+ * Check the code is either a valid ISBN-10 or ISBN-13 code.
+ * @param code The code to validate.
+ * @return true if a valid ISBN-10 or
+ * ISBN-13 code, otherwise false.
+ */
+func (v *ISBNValidator) IsValid(code string) bool {
+	return IsValidISBN13(code) || IsValidISBN10(code)
+}
+
+/**
+ * Check the code is a valid ISBN-10 code.
+ * @param code The code to validate.
+ * @return true if a valid ISBN-10
+ * code, otherwise false.
+ */
+func IsValidISBN10(code string) bool {
+	_, err := isbn10Validator.validate(code)
+	return err == nil
+}
+
+/**
+ * Check the code is a valid ISBN-13 code.
+ * @param code The code to validate.
+ * @return true if a valid ISBN-13
+ * code, otherwise false.
+ */
+func IsValidISBN13(code string) bool {
+	_, err := isbn13Validator.validate(code)
+	return err == nil
+}
+
+/**
+ * Validates an ISBN-10 or ISBN-13 code using the default validator.
+ * @param code The code to validate.
+ * @return nil if the code is valid, otherwise a *ValidationError.
+ */
+func Validate(code string) error {
+	return defaultValidator.Validate(code)
+}
+
+/**
+ * Validates an ISBN-10 or ISBN-13 code. The error is that of the
+ * ISBN-13 validation if the code has the format of an ISBN-13,
+ * otherwise that of the ISBN-10 validation. Leading and trailing
+ * whitespace is ignored, but offsets in the returned error are
+ * relative to code as given.
+ * @param code The code to validate.
+ * @return nil if the code is valid, otherwise a *ValidationError.
+ */
+func (v *ISBNValidator) Validate(code string) error {
+	if _, err := v.validate(code); err != nil {
+		return err
+	}
+	return nil
+}
+
+/**
+ * Normalizes an ISBN-10 or ISBN-13 code using the default validator,
+ * which converts ISBN-10 codes to ISBN-13.
+ * @param code The code to validate.
+ * @return A normalized ISBN code, and nil if valid, otherwise a
+ * *ValidationError.
+ */
+func Normalize(code string) (string, error) {
+	return defaultValidator.Normalize(code)
+}
+
+/**
+ * Check the code is either a valid ISBN-10 or ISBN-13 code and return
+ * it with formatting characters removed. If valid and the Convert
+ * option is set, an ISBN-10 code is converted to ISBN-13.
+ * @param code The code to validate.
+ * @return A normalized ISBN code, and nil if valid, otherwise a
+ * *ValidationError.
+ */
+func (v *ISBNValidator) Normalize(code string) (string, error) {
+	isbn, err := v.validate(code)
+	if err != nil {
+		return "", err
+	}
+	return isbn, nil
+}
+
+/**
+ * Check the code is a valid ISBN-10 code and return it with
+ * formatting characters removed.
+ * @param code The code to validate.
+ * @return A normalized ISBN-10 code, and nil if valid, otherwise a
+ * *ValidationError.
+ */
+func NormalizeISBN10(code string) (string, error) {
+	isbn, err := isbn10Validator.validate(code)
+	if err != nil {
+		return "", err
+	}
+	return isbn, nil
+}
+
+/**
+ * Check the code is a valid ISBN-13 code and return it with
+ * formatting characters removed.
+ * @param code The code to validate.
+ * @return A normalized ISBN-13 code, and nil if valid, otherwise a
+ * *ValidationError.
+ */
+func NormalizeISBN13(code string) (string, error) {
+	isbn, err := isbn13Validator.validate(code)
+	if err != nil {
+		return "", err
+	}
+	return isbn, nil
+}
+
+/**
+ * Convert an ISBN-10 code to an ISBN-13 code.
+ *
+ * This method requires a valid ISBN-10, in the plain or hyphenated
+ * form. Unlike Commons Validator it validates the code first, rather
+ * than converting any 10 characters.
+ * @param isbn10 The ISBN-10 code to convert
+ * @return A converted ISBN-13 code, and nil if the ISBN-10 code is
+ * valid, otherwise a *ValidationError.
+ */
+func ConvertToISBN13(isbn10 string) (string, error) {
+	isbn, err := isbn10Validator.validate(isbn10)
+	if err != nil {
+		return "", err
+	}
+	return convertToISBN13(isbn), nil
+}
+
+// convertToISBN13 converts a valid, normalized ISBN-10 code.
+func convertToISBN13(isbn10 string) string {
+	isbn13 := "978" + isbn10[:ISBN_10_LEN-1]
+	checkDigit, err := checkdigit.EAN13_CHECK_DIGIT.Calculate(isbn13)
+	if err != nil {
+		// isbn13 consists of digits and starts with 978
+		panic(err)
+	}
+	return isbn13 + checkDigit
+}
+
+// validate returns the normalized code, or the reason it is invalid.
+func (v *ISBNValidator) validate(code string) (string, *ValidationError) {
+	isbn, err13 := isbn13Validator.validate(code)
+	if err13 == nil {
+		return isbn, nil
+	}
+	isbn, err10 := isbn10Validator.validate(code)
+	if err10 == nil {
+		if !v.noConvert {
+			isbn = convertToISBN13(isbn)
+		}
+		return isbn, nil
+	}
+	if err13.Err != ErrInvalidFormat {
+		return "", err13
+	}
+	return "", err10
+}
@@ -0,0 +1,301 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/ISBNValidatorTest.java?view=log
+ */
+package isbnvalidator
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+var validISBN10Format = []string{
+	"1234567890",
+	"123456789X",
+	"12345-1234567-123456-X",
+	"12345 1234567 123456 X",
+	"1-2-3-4",
+	"1 2 3 4",
+}
+
+var invalidISBN10Format = []string{
+	"",                        // empty
+	"   ",                     // empty
+	"1",                       // too short
+	"123456789",               // too short
+	"12345678901",             // too long
+	"12345678X0",              // X not at end
+	"123456-1234567-123456-X", // Group too long
+	"12345-12345678-123456-X", // Publisher too long
+	"12345-1234567-1234567-X", // Title too long
+	"12345-1234567-123456-X2", // Check Digit too long
+	"--1 930110 99 5",         // format
+	"1 930110 99 5--",         // format
+	"1 930110-99 5-",          // format
+	"1.2.3.4",                 // Invalid Separator
+	"1=2=3=4",                 // Invalid Separator
+	"1_2_3_4",                 // Invalid Separator
+	"123456789Y",              // Other character at the end
+	"dsasdsadsa",              // invalid characters
+	"I love sparrows!",        // invalid characters
+	"068-556-98-45",           // format
+}
+
+var validISBN13Format = []string{
+	"9781234567890",
+	"9791234567890",
+	"978-12345-1234567-123456-1",
+	"979-12345-1234567-123456-1",
+	"978 12345 1234567 123456 1",
+	"979 12345 1234567 123456 1",
+	"978-1-2-3-4",
+	"979-1-2-3-4",
+	"978 1 2 3 4",
+	"979 1 2 3 4",
+}
+
+var invalidISBN13Format = []string{
+	"",                            // empty
+	"   ",                         // empty
+	"1",                           // too short
+	"978123456789",                // too short
+	"97812345678901",              // too long
+	"978-123456-1234567-123456-1", // Group too long
+	"978-12345-12345678-123456-1", // Publisher too long
+	"978-12345-1234567-1234567-1", // Title too long
+	"978-12345-1234567-123456-12", // Check Digit too long
+	"--978 1 930110 99 1",         // format
+	"978 1 930110 99 1--",         // format
+	"978 1 930110-99 1-",          // format
+	"123-4-567890-12-8",           // format
+	"978.1.2.3.4",                 // Invalid Separator
+	"978=1=2=3=4",                 // Invalid Separator
+	"978_1_2_3_4",                 // Invalid Separator
+	"978123456789X",               // invalid character
+	"978-0-201-63385-X",           // invalid character
+	"dsasdsadsa",                  // invalid characters
+	"I love sparrows!",            // invalid characters
+	"979-1-234-567-89-6",          // format
+}
+
+/**
+ * Test the ISBN-10 and ISBN-13 regular expressions.
+ */
+func TestFormat(t *testing.T) {
+	isbn10 := regexp.MustCompile(ISBN10_REGEX)
+	for _, code := range validISBN10Format {
+		if !isbn10.MatchString(code) {
+			t.Errorf("expected valid ISBN-10 format: %s", code)
+		}
+	}
+	for _, code := range invalidISBN10Format {
+		if isbn10.MatchString(code) {
+			t.Errorf("expected invalid ISBN-10 format: %s", code)
+		}
+	}
+
+	isbn13 := regexp.MustCompile(ISBN13_REGEX)
+	for _, code := range validISBN13Format {
+		if !isbn13.MatchString(code) {
+			t.Errorf("expected valid ISBN-13 format: %s", code)
+		}
+	}
+	for _, code := range invalidISBN13Format {
+		if isbn13.MatchString(code) {
+			t.Errorf("expected invalid ISBN-13 format: %s", code)
+		}
+	}
+}
+
+func TestIsValidISBN10(t *testing.T) {
+	valid := []string{
+		"1930110995",
+		"1-930110-99-5",
+		"1 930110 99 5",
+		"020163385X",
+		"0-201-63385-X",
+		"0 201 63385 X",
+		" 1932394354 ",
+		"1590596277",
+	}
+	for _, code := range valid {
+		if !IsValidISBN10(code) {
+			t.Errorf("expected valid ISBN-10: %s", code)
+		}
+		if !IsValid(code) {
+			t.Errorf("expected valid ISBN: %s", code)
+		}
+		if IsValidISBN13(code) {
+			t.Errorf("expected invalid ISBN-13: %s", code)
+		}
+	}
+}
+
+func TestIsValidISBN13(t *testing.T) {
+	valid := []string{
+		"9781930110991",
+		"978-1-930110-99-1",
+		"978 1 930110 99 1",
+		"9780201633856",
+		"978-0-201-63385-6",
+		"978 0 201 63385 6",
+	}
+	for _, code := range valid {
+		if !IsValidISBN13(code) {
+			t.Errorf("expected valid ISBN-13: %s", code)
+		}
+		if !IsValid(code) {
+			t.Errorf("expected valid ISBN: %s", code)
+		}
+		if IsValidISBN10(code) {
+			t.Errorf("expected invalid ISBN-10: %s", code)
+		}
+	}
+}
+
+/**
+ * Test every other check digit of a valid code.
+ */
+func TestInvalid(t *testing.T) {
+	for _, c := range "0123456789X" {
+		if code := "193011099" + string(c); c != '5' && IsValid(code) {
+			t.Errorf("expected invalid ISBN: %s", code)
+		}
+		if code := "978193011099" + string(c); c != '1' && IsValid(code) {
+			t.Errorf("expected invalid ISBN: %s", code)
+		}
+	}
+	for _, code := range append(invalidISBN10Format, invalidISBN13Format...) {
+		if IsValid(code) {
+			t.Errorf("expected invalid ISBN: %s", code)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	noConvert := New(Convert(false))
+	zero := &ISBNValidator{}
+	tests := []struct {
+		code      string
+		convert   string
+		noConvert string
+	}{
+		{"1930110995", "9781930110991", "1930110995"},
+		{"1-930110-99-5", "9781930110991", "1930110995"},
+		{" 1 930110 99 5 ", "9781930110991", "1930110995"},
+		{"020163385X", "9780201633856", "020163385X"},
+		{"0-201-63385-X", "9780201633856", "020163385X"},
+		{"9781930110991", "9781930110991", "9781930110991"},
+		{"978-1-930110-99-1", "9781930110991", "9781930110991"},
+		{"978 0 201 63385 6", "9780201633856", "9780201633856"},
+	}
+	for _, tt := range tests {
+		if got, err := Normalize(tt.code); got != tt.convert || err != nil {
+			t.Errorf("Normalize(%q): got %q, %v, want %q", tt.code, got, err, tt.convert)
+		}
+		if got, err := zero.Normalize(tt.code); got != tt.convert || err != nil {
+			t.Errorf("Normalize(%q) with the zero value: got %q, %v, want %q", tt.code, got, err, tt.convert)
+		}
+		if got, err := noConvert.Normalize(tt.code); got != tt.noConvert || err != nil {
+			t.Errorf("Normalize(%q) without conversion: got %q, %v, want %q", tt.code, got, err, tt.noConvert)
+		}
+	}
+
+	if got, err := NormalizeISBN10("0-201-63385-X"); got != "020163385X" || err != nil {
+		t.Errorf("NormalizeISBN10: got %q, %v", got, err)
+	}
+	if got, err := NormalizeISBN10("9780201633856"); got != "" || err == nil {
+		t.Errorf("NormalizeISBN10: expected error, got %q", got)
+	}
+	if got, err := NormalizeISBN13("978-0-201-63385-6"); got != "9780201633856" || err != nil {
+		t.Errorf("NormalizeISBN13: got %q, %v", got, err)
+	}
+	if got, err := NormalizeISBN13("020163385X"); got != "" || err == nil {
+		t.Errorf("NormalizeISBN13: expected error, got %q", got)
+	}
+}
+
+func TestConvertToISBN13(t *testing.T) {
+	tests := []struct {
+		isbn10 string
+		isbn13 string
+	}{
+		{"1930110995", "9781930110991"},
+		{"1-930110-99-5", "9781930110991"},
+		{"020163385X", "9780201633856"},
+		{"0 201 63385 X", "9780201633856"},
+		{"1932394354", "9781932394351"},
+		{"1590596277", "9781590596272"},
+	}
+	for _, tt := range tests {
+		got, err := ConvertToISBN13(tt.isbn10)
+		if got != tt.isbn13 || err != nil {
+			t.Errorf("ConvertToISBN13(%q): got %q, %v, want %q", tt.isbn10, got, err, tt.isbn13)
+		}
+		if !IsValidISBN13(got) {
+			t.Errorf("ConvertToISBN13(%q): invalid ISBN-13 %q", tt.isbn10, got)
+		}
+	}
+
+	for _, code := range []string{"", "1930110996", "9781930110991", "123456789"} {
+		if got, err := ConvertToISBN13(code); got != "" || err == nil {
+			t.Errorf("ConvertToISBN13(%q): expected error, got %q", code, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		code    string
+		err     error
+		segment string
+		offset  int
+	}{
+		{"1930110995", nil, "", 0},
+		{"978-1-930110-99-1", nil, "", 0},
+		{"", ErrEmpty, "", 0},
+		{"  ", ErrEmpty, "", 0},
+		{"1930110996", ErrInvalidCheckDigit, "6", 9},
+		{" 1-930110-99-6", ErrInvalidCheckDigit, "6", 13},
+		{"9781930110992", ErrInvalidCheckDigit, "2", 12},
+		{"978-1-930110-99-2 ", ErrInvalidCheckDigit, "2", 16},
+		{"1-2-3-4", ErrInvalidLength, "1-2-3-4", 0},
+		{"\t978-1-2-3-4", ErrInvalidLength, "978-1-2-3-4", 1},
+		{"12345678X0", ErrInvalidFormat, "12345678X0", 0},
+		{"978-0-201-63385-X", ErrInvalidFormat, "978-0-201-63385-X", 0},
+		{" I love sparrows! ", ErrInvalidFormat, "I love sparrows!", 1},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.code)
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q): unexpected error %v", tt.code, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Validate(%q): expected *ValidationError, got %v", tt.code, err)
+			continue
+		}
+		if verr.Err != tt.err || verr.Segment != tt.segment || verr.Offset != tt.offset {
+			t.Errorf("Validate(%q): got %v, %q, %d, want %v, %q, %d",
+				tt.code, verr.Err, verr.Segment, verr.Offset, tt.err, tt.segment, tt.offset)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/ISSNValidator.java?view=log
+ */
+package issnvalidator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dsparling/go-commons-validator/checkdigit"
+	"github.com/dsparling/go-commons-validator/regexvalidator"
+)
+
+/**
+ * International Standard Serial Number (ISSN)
+ * is an eight-digit serial number used to
+ * uniquely identify a serial publication.
+ *
+ *  The format is:
+ *
+ *  ISSN dddd-dddC
+ *  where:
+ *  d = decimal digit (0-9)
+ *  C = checksum (0-9 or X)
+ *
+ *  The checksum is formed by adding the first 7 digits multiplied by
+ *  the position in the entire number (counting from the right).
+ *  For example, abcd-efg would be 8a + 7b + 6c + 5d + 4e +3f +2g.
+ *  The check digit is modulus 11, where the value 10 is represented by 'X'
+ *  For example:
+ *  ISSN 0317-8471
+ *  ISSN 1050-124X
+ *
+ * The ISSN prefix is optional and, unlike Commons Validator, so is the
+ * hyphen, so that plain forms such as 03178471 are valid too.
+ *
+ * This class strips off the 'ISSN ' prefix if it is present before passing
+ * the remainder to the checksum routine.
+ */
+const (
+	ISSN_REGEX = "^(?:ISSN )?(\\d{4})-?(\\d{3}[0-9X])$" // We don't include the '-' in the code, so it is 8 chars
+	ISSN_LEN   = 8
+
+	ISSN_PREFIX = "977"
+
+	EAN_ISSN_REGEX = "^(977)(?:(\\d{10}))$"
+	EAN_ISSN_LEN   = 13
+)
+
+var (
+	ErrEmpty             = errors.New("issnvalidator: empty ISSN")
+	ErrInvalidFormat     = errors.New("issnvalidator: invalid format")
+	ErrInvalidCheckDigit = errors.New("issnvalidator: invalid check digit")
+	ErrInvalidSuffix     = errors.New("issnvalidator: suffix must be two digits")
+)
+
+/**
+ * ValidationError reports why an ISSN or EAN-13 failed validation.
+ * For ErrInvalidCheckDigit, Segment is the check character, the last
+ * of the code. For ErrInvalidFormat it is the whole code as given,
+ * without surrounding white space, except from ExtractFromEAN13 when
+ * the EAN-13 holds the ISSN 0000000: then it is those seven digits.
+ * Offset is the byte offset of Segment in the string passed in.
+ * ErrEmpty has neither.
+ */
+type ValidationError struct {
+	Err     error
+	Segment string
+	Offset  int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Segment, e.Offset)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// codeValidator is CodeValidator in Commons Validator: a code is valid
+// if it matches regex and the check digit of the groups matched is
+// valid. The regular expressions fix the length of the code.
+type codeValidator struct {
+	regex      *regexvalidator.RegexValidator
+	checkDigit checkdigit.CheckDigit
+}
+
+var (
+	issnValidator = &codeValidator{
+		regex:      regexvalidator.MustNew([]string{ISSN_REGEX}),
+		checkDigit: checkdigit.ISSN_CHECK_DIGIT,
+	}
+	eanValidator = &codeValidator{
+		regex:      regexvalidator.MustNew([]string{EAN_ISSN_REGEX}),
+		checkDigit: checkdigit.EAN13_CHECK_DIGIT,
+	}
+)
+
+// validate returns code without formatting characters and surrounding
+// whitespace if it is valid, otherwise the reason it isn't, and the
+// length of the leading whitespace.
+func (c *codeValidator) validate(code string) (string, int, *ValidationError) {
+	lead := len(code) - len(strings.TrimLeftFunc(code, unicode.IsSpace))
+	trimmed := strings.TrimSpace(code)
+	if trimmed == "" {
+		return "", lead, &ValidationError{Err: ErrEmpty}
+	}
+	joined, ok := c.regex.ValidateAndJoin(trimmed)
+	if !ok {
+		return "", lead, &ValidationError{Err: ErrInvalidFormat, Segment: trimmed, Offset: lead}
+	}
+	if !c.checkDigit.IsValid(joined) {
+		last := len(trimmed) - 1
+		return "", lead, &ValidationError{Err: ErrInvalidCheckDigit, Segment: trimmed[last:], Offset: lead + last}
+	}
+	return joined, lead, nil
+}
+
+/**
+ * Check the code is a valid ISSN code after any transformation
+ * by the validate routine.
+ * @param code The code to validate.
+ * @return true if a valid ISSN
+ * code, otherwise false.
+ */
+func IsValid(code string) bool {
+	_, _, err := issnValidator.validate(code)
+	return err == nil
+}
+
+/**
+ * Validates an ISSN code. Leading and trailing whitespace is ignored,
+ * but offsets in the returned error are relative to code as given.
+ * @param code The code to validate.
+ * @return nil if the code is valid, otherwise a *ValidationError.
+ */
+func Validate(code string) error {
+	if _, _, err := issnValidator.validate(code); err != nil {
+		return err
+	}
+	return nil
+}
+
+/**
+ * Check the code is a valid ISSN code and return it without the ISSN
+ * prefix and the hyphen, as 8 characters.
+ * @param code The code to validate.
+ * @return A normalized ISSN code, and nil if valid, otherwise a
+ * *ValidationError.
+ */
+func Normalize(code string) (string, error) {
+	issn, _, err := issnValidator.validate(code)
+	if err != nil {
+		return "", err
+	}
+	return issn, nil
+}
+
+/**
+ * Convert an ISSN code to an EAN-13 code.
+ *
+ * This method requires a valid ISSN code.
+ * It may contain a leading 'ISSN ' prefix,
+ * as the input is passed through Normalize.
+ *
+ * @param issn The ISSN code to convert
+ * @param suffix the two digit suffix, e.g. "00"
+ * @return A converted EAN-13 code, and nil if the ISSN code is valid,
+ * otherwise ErrInvalidSuffix or a *ValidationError.
+ */
+func ConvertToEAN13(issn, suffix string) (string, error) {
+	if len(suffix) != 2 || !isDigit(suffix[0]) || !isDigit(suffix[1]) {
+		return "", ErrInvalidSuffix
+	}
+	code, err := Normalize(issn)
+	if err != nil {
+		return "", err
+	}
+	// Strip the check digit, and add the prefix and the suffix
+	ean13 := ISSN_PREFIX + code[:ISSN_LEN-1] + suffix
+	checkDigit, cerr := checkdigit.EAN13_CHECK_DIGIT.Calculate(ean13)
+	if cerr != nil {
+		// ean13 consists of digits and starts with 977
+		panic(cerr)
+	}
+	return ean13 + checkDigit, nil
+}
+
+/**
+ * Extract an ISSN code from an ISSN-EAN-13 code.
+ *
+ * This method requires a valid ISSN-EAN-13 code, that is a valid EAN-13
+ * starting with 977.
+ *
+ * @param ean13 The EAN-13 code to convert
+ * @return the valid ISSN code, and nil if the EAN-13 code is a valid
+ * ISSN-EAN-13, otherwise a *ValidationError.
+ */
+func ExtractFromEAN13(ean13 string) (string, error) {
+	code, lead, err := eanValidator.validate(ean13)
+	if err != nil {
+		return "", err
+	}
+	// The ISSN has the 7 digits that follow the 977 prefix
+	issnBase := code[len(ISSN_PREFIX) : len(ISSN_PREFIX)+ISSN_LEN-1]
+	checkDigit, cerr := checkdigit.ISSN_CHECK_DIGIT.Calculate(issnBase)
+	if cerr != nil {
+		// The only error is a sum of zero, for an ISSN of 0000000
+		return "", &ValidationError{Err: ErrInvalidFormat, Segment: issnBase, Offset: lead + len(ISSN_PREFIX)}
+	}
+	return issnBase + checkDigit, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
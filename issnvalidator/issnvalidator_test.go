@@ -0,0 +1,196 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/ISSNValidatorTest.java?view=log
+ */
+package issnvalidator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dsparling/go-commons-validator/checkdigit"
+)
+
+var validFormat = []string{
+	"ISSN 0317-8471",
+	"1050-124X",
+	"ISSN 1562-6865",
+	"1063-7710",
+	"1748-7188",
+	"ISSN 0264-2875",
+	"1750-0095",
+	"1188-1534",
+	"1911-1479",
+	"ISSN 1911-1460",
+	"0001-6772",
+	"1365-201X",
+	"0264-3596",
+	"1144-875X",
+	"03178471",
+	"ISSN 1050124X",
+	" 2434-561X ",
+}
+
+var invalidFormat = []string{
+	"",
+	"   ",
+	"ISBN 0317-8471",
+	"'1050-1241",
+	"ISSN1562-6865",
+	"1748-7188'",
+	"ISSN  0264-2875",
+	"1750 0095",
+	"1188_1534",
+	"1911-1478",
+	"1050-124x",
+	"1050--124X",
+}
+
+func TestIsValid(t *testing.T) {
+	for _, code := range validFormat {
+		if !IsValid(code) {
+			t.Errorf("expected valid ISSN: %s", code)
+		}
+	}
+}
+
+func TestInValid(t *testing.T) {
+	for _, code := range invalidFormat {
+		if IsValid(code) {
+			t.Errorf("expected invalid ISSN: %s", code)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code string
+		issn string
+	}{
+		{"ISSN 0317-8471", "03178471"},
+		{"1050-124X", "1050124X"},
+		{"1050124X", "1050124X"},
+		{" 1144-875X\n", "1144875X"},
+	}
+	for _, tt := range tests {
+		if got, err := Normalize(tt.code); got != tt.issn || err != nil {
+			t.Errorf("Normalize(%q): got %q, %v, want %q", tt.code, got, err, tt.issn)
+		}
+	}
+	if got, err := Normalize("1911-1478"); got != "" || err == nil {
+		t.Errorf("Normalize: expected error, got %q", got)
+	}
+}
+
+func TestConvertToEAN13(t *testing.T) {
+	tests := []struct {
+		issn   string
+		suffix string
+		ean13  string
+	}{
+		{"0317-8471", "00", "9770317847001"},
+		{"ISSN 1144-875X", "00", "9771144875007"},
+		{"1144875X", "05", "9771144875052"},
+	}
+	for _, tt := range tests {
+		if got, err := ConvertToEAN13(tt.issn, tt.suffix); got != tt.ean13 || err != nil {
+			t.Errorf("ConvertToEAN13(%q, %q): got %q, %v, want %q", tt.issn, tt.suffix, got, err, tt.ean13)
+		}
+	}
+
+	for _, code := range validFormat {
+		ean13, err := ConvertToEAN13(code, "00")
+		if err != nil {
+			t.Errorf("ConvertToEAN13(%q): %v", code, err)
+			continue
+		}
+		if !checkdigit.EAN13_CHECK_DIGIT.IsValid(ean13) {
+			t.Errorf("ConvertToEAN13(%q): invalid EAN-13 %q", code, ean13)
+		}
+		issn, _ := Normalize(code)
+		if got, err := ExtractFromEAN13(ean13); got != issn || err != nil {
+			t.Errorf("ExtractFromEAN13(%q): got %q, %v, want %q", ean13, got, err, issn)
+		}
+	}
+
+	for _, suffix := range []string{"", "0", "000", "0A", " 0"} {
+		if _, err := ConvertToEAN13("0317-8471", suffix); err != ErrInvalidSuffix {
+			t.Errorf("ConvertToEAN13 suffix %q: expected ErrInvalidSuffix, got %v", suffix, err)
+		}
+	}
+	if _, err := ConvertToEAN13("1911-1478", "00"); !errors.Is(err, ErrInvalidCheckDigit) {
+		t.Errorf("ConvertToEAN13: expected ErrInvalidCheckDigit, got %v", err)
+	}
+}
+
+func TestExtractFromEAN13(t *testing.T) {
+	tests := []struct {
+		ean13 string
+		issn  string
+		err   error
+	}{
+		{"9770317847001", "03178471", nil},
+		{" 9771144875007", "1144875X", nil},
+		{"9771234567003", "12345679", nil},
+		{"9771234567001", "", ErrInvalidCheckDigit},
+		{"9780072129519", "", ErrInvalidFormat}, // an ISBN
+		{"977123456700", "", ErrInvalidFormat},
+		{"9770000000003", "", ErrInvalidFormat}, // ISSN 0000000,
+		{"", "", ErrEmpty},
+	}
+	for _, tt := range tests {
+		got, err := ExtractFromEAN13(tt.ean13)
+		if got != tt.issn || !errors.Is(err, tt.err) {
+			t.Errorf("ExtractFromEAN13(%q): got %q, %v, want %q, %v", tt.ean13, got, err, tt.issn, tt.err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		code    string
+		err     error
+		segment string
+		offset  int
+	}{
+		{"0317-8471", nil, "", 0},
+		{"", ErrEmpty, "", 0},
+		{"  ", ErrEmpty, "", 0},
+		{"1911-1478", ErrInvalidCheckDigit, "8", 8},
+		{" ISSN 1911-1478", ErrInvalidCheckDigit, "8", 14},
+		{"1750 0095", ErrInvalidFormat, "1750 0095", 0},
+		{"\tISBN 0317-8471", ErrInvalidFormat, "ISBN 0317-8471", 1},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.code)
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("Validate(%q): unexpected error %v", tt.code, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Validate(%q): expected *ValidationError, got %v", tt.code, err)
+			continue
+		}
+		if verr.Err != tt.err || verr.Segment != tt.segment || verr.Offset != tt.offset {
+			t.Errorf("Validate(%q): got %v, %q, %d, want %v, %q, %d",
+				tt.code, verr.Err, verr.Segment, verr.Offset, tt.err, tt.segment, tt.offset)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/RegexValidator.java?view=log
+ */
+package regexvalidator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrMissingPatterns = errors.New("regexvalidator: regular expressions are missing")
+	ErrMissingPattern  = errors.New("regexvalidator: regular expression is missing")
+)
+
+/**
+ * PatternError reports a regular expression that is missing or doesn't
+ * compile. Index is its position in the patterns given to New, and Err
+ * is ErrMissingPattern or the error from the regexp package.
+ */
+type PatternError struct {
+	Index int
+	Err   error
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("regexvalidator: regular expression[%d]: %v", e.Index, e.Err)
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * Regular Expression validation (using the regexp package).
+ *
+ * Construct the validator either for a single regular expression or a
+ * set (array) of regular expressions. By default validation is case
+ * sensitive but the CaseSensitive option can be used to make it case
+ * in-sensitive.
+ *
+ * A value is valid if one of the regular expressions matches all of it.
+ * The capture groups of the first regular expression that matches are
+ * returned by Match and ValidateAndJoin.
+ *
+ * A RegexValidator is immutable once created and safe for concurrent use.
+ */
+type RegexValidator struct {
+	caseSensitive bool
+	patterns      []string
+	regexes       []*regexp.Regexp
+}
+
+/**
+ * Option configures a RegexValidator.
+ */
+type Option func(*RegexValidator)
+
+/**
+ * CaseSensitive sets whether matching is case sensitive. The default
+ * is true.
+ * @param caseSensitive when true matching is case sensitive, otherwise
+ * matching is case in-sensitive
+ */
+func CaseSensitive(caseSensitive bool) Option {
+	return func(v *RegexValidator) {
+		v.caseSensitive = caseSensitive
+	}
+}
+
+/**
+ * Construct a validator that matches any one of the set of regular
+ * expressions, in the RE2 syntax of the regexp package.
+ * @param patterns The set of regular expressions this validator will
+ * validate against
+ * @param opts the options to apply
+ * @return the validator, and nil if every pattern compiles, otherwise
+ * ErrMissingPatterns or a *PatternError.
+ */
+func New(patterns []string, opts ...Option) (*RegexValidator, error) {
+	v := &RegexValidator{caseSensitive: true}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if len(patterns) == 0 {
+		return nil, ErrMissingPatterns
+	}
+	v.patterns = make([]string, len(patterns))
+	v.regexes = make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		if pattern == "" {
+			return nil, &PatternError{Index: i, Err: ErrMissingPattern}
+		}
+		// Compile the pattern on its own first, so that one with an
+		// unbalanced ")" can't close the group added below
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, &PatternError{Index: i, Err: err}
+		}
+		// Like Matcher.matches() in Java, the whole value has to match
+		flags := ""
+		if !v.caseSensitive {
+			flags = "i"
+		}
+		regex, err := regexp.Compile("^(?" + flags + ":" + pattern + ")$")
+		if err != nil {
+			return nil, &PatternError{Index: i, Err: err}
+		}
+		v.patterns[i] = pattern
+		v.regexes[i] = regex
+	}
+	return v, nil
+}
+
+/**
+ * MustNew is like New but panics if a pattern is missing or doesn't
+ * compile. It simplifies the initialization of package variables.
+ * @param patterns The set of regular expressions this validator will
+ * validate against
+ * @param opts the options to apply
+ * @return the validator
+ */
+func MustNew(patterns []string, opts ...Option) *RegexValidator {
+	v, err := New(patterns, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+/**
+ * Validate a value against the set of regular expressions.
+ * @param value The value to validate.
+ * @return true if the value is valid
+ * otherwise false.
+ */
+func (v *RegexValidator) IsValid(value string) bool {
+	for _, regex := range v.regexes {
+		if regex.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Validate a value against the set of regular expressions
+ * returning the array of matched groups.
+ * @param value The value to validate.
+ * @return String array of the groups matched if
+ * valid or nil if invalid. Groups that didn't take part in the match
+ * are empty.
+ */
+func (v *RegexValidator) Match(value string) []string {
+	for _, regex := range v.regexes {
+		if groups := regex.FindStringSubmatch(value); groups != nil {
+			return groups[1:]
+		}
+	}
+	return nil
+}
+
+/**
+ * Validate a value against the set of regular expressions
+ * returning a String value of the aggregated groups.
+ * @param value The value to validate.
+ * @return Aggregated String value comprised of the
+ * groups matched, and true if valid, otherwise "" and false.
+ */
+func (v *RegexValidator) ValidateAndJoin(value string) (string, bool) {
+	groups := v.Match(value)
+	if groups == nil {
+		return "", false
+	}
+	return strings.Join(groups, ""), true
+}
+
+/**
+ * Returns the patterns this validator was created with.
+ * @return the regular expressions, as given to New
+ */
+func (v *RegexValidator) Patterns() []string {
+	patterns := make([]string, len(v.patterns))
+	copy(patterns, v.patterns)
+	return patterns
+}
+
+/**
+ * Provide a String representation of this validator.
+ * @return A String representation of this validator
+ */
+func (v *RegexValidator) String() string {
+	return "RegexValidator{" + strings.Join(v.patterns, ",") + "}"
+}
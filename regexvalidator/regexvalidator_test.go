@@ -0,0 +1,329 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/test/java/org/apache/commons/validator/routines/RegexValidatorTest.java?view=log
+ */
+package regexvalidator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	REGEX = `^([abc]*)(?:\-)([DEF]*)(?:\-)([123]*)$`
+
+	COMPONENT_1 = `([abc]{3})`
+	COMPONENT_2 = `([DEF]{3})`
+	COMPONENT_3 = `([123]{3})`
+	SEPARATOR_1 = `(?:\-)`
+	SEPARATOR_2 = `(?:\s)`
+	REGEX_1     = "^" + COMPONENT_1 + SEPARATOR_1 + COMPONENT_2 + SEPARATOR_1 + COMPONENT_3 + "$"
+	REGEX_2     = "^" + COMPONENT_1 + SEPARATOR_2 + COMPONENT_2 + SEPARATOR_2 + COMPONENT_3 + "$"
+	REGEX_3     = "^" + COMPONENT_1 + COMPONENT_2 + COMPONENT_3 + "$"
+)
+
+var MULTIPLE_REGEX = []string{REGEX_1, REGEX_2, REGEX_3}
+
+/**
+ * Test instance methods with single regular expression.
+ */
+func TestSingle(t *testing.T) {
+	sensitive := MustNew([]string{REGEX})
+	insensitive := MustNew([]string{REGEX}, CaseSensitive(false))
+
+	// isValid()
+	if !sensitive.IsValid("ac-DE-1") {
+		t.Errorf("Sensitive isValid() valid")
+	}
+	if sensitive.IsValid("AB-de-1") {
+		t.Errorf("Sensitive isValid() invalid")
+	}
+	if !insensitive.IsValid("AB-de-1") {
+		t.Errorf("Insensitive isValid() valid")
+	}
+	if insensitive.IsValid("ABd-de-1") {
+		t.Errorf("Insensitive isValid() invalid")
+	}
+
+	// validate()
+	if s, ok := sensitive.ValidateAndJoin("ac-DE-1"); !ok || s != "acDE1" {
+		t.Errorf("Sensitive validate() valid: got %q, %v", s, ok)
+	}
+	if s, ok := sensitive.ValidateAndJoin("AB-de-1"); ok || s != "" {
+		t.Errorf("Sensitive validate() invalid: got %q, %v", s, ok)
+	}
+	if s, ok := insensitive.ValidateAndJoin("AB-de-1"); !ok || s != "ABde1" {
+		t.Errorf("Insensitive validate() valid: got %q, %v", s, ok)
+	}
+	if s, ok := insensitive.ValidateAndJoin("ABd-de-1"); ok || s != "" {
+		t.Errorf("Insensitive validate() invalid: got %q, %v", s, ok)
+	}
+
+	// match()
+	if got, want := sensitive.Match("ac-DE-1"), []string{"ac", "DE", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sensitive match() valid: got %q, want %q", got, want)
+	}
+	if got := sensitive.Match("AB-de-1"); got != nil {
+		t.Errorf("Sensitive match() invalid: got %q", got)
+	}
+	if got, want := insensitive.Match("AB-de-1"), []string{"AB", "de", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Insensitive match() valid: got %q, want %q", got, want)
+	}
+	if got := insensitive.Match("ABd-de-1"); got != nil {
+		t.Errorf("Insensitive match() invalid: got %q", got)
+	}
+
+	// single group
+	single := MustNew([]string{"^([A-Z]*)$"})
+	if s, ok := single.ValidateAndJoin("AB"); !ok || s != "AB" {
+		t.Errorf("validate one: got %q, %v", s, ok)
+	}
+	if got, want := single.Match("AB"), []string{"AB"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("match one: got %q, want %q", got, want)
+	}
+}
+
+/**
+ * Test with multiple regular expressions (case sensitive).
+ */
+func TestMultipleSensitive(t *testing.T) {
+	multiple := MustNew(MULTIPLE_REGEX)
+	single1 := MustNew([]string{REGEX_1})
+	single2 := MustNew([]string{REGEX_2})
+	single3 := MustNew([]string{REGEX_3})
+
+	// Valid
+	value := "aac FDE 321"
+	expect := "aacFDE321"
+	array := []string{"aac", "FDE", "321"}
+
+	// isValid()
+	if !multiple.IsValid(value) {
+		t.Errorf("Sensitive isValid() Multiple")
+	}
+	if single1.IsValid(value) {
+		t.Errorf("Sensitive isValid() 1st")
+	}
+	if !single2.IsValid(value) {
+		t.Errorf("Sensitive isValid() 2nd")
+	}
+	if single3.IsValid(value) {
+		t.Errorf("Sensitive isValid() 3rd")
+	}
+
+	// validate()
+	if s, ok := multiple.ValidateAndJoin(value); !ok || s != expect {
+		t.Errorf("Sensitive validate() Multiple: got %q, %v", s, ok)
+	}
+	if _, ok := single1.ValidateAndJoin(value); ok {
+		t.Errorf("Sensitive validate() 1st")
+	}
+	if s, ok := single2.ValidateAndJoin(value); !ok || s != expect {
+		t.Errorf("Sensitive validate() 2nd: got %q, %v", s, ok)
+	}
+	if _, ok := single3.ValidateAndJoin(value); ok {
+		t.Errorf("Sensitive validate() 3rd")
+	}
+
+	// match()
+	if got := multiple.Match(value); !reflect.DeepEqual(got, array) {
+		t.Errorf("Sensitive match() Multiple: got %q, want %q", got, array)
+	}
+	if got := single1.Match(value); got != nil {
+		t.Errorf("Sensitive match() 1st: got %q", got)
+	}
+	if got := single2.Match(value); !reflect.DeepEqual(got, array) {
+		t.Errorf("Sensitive match() 2nd: got %q, want %q", got, array)
+	}
+	if got := single3.Match(value); got != nil {
+		t.Errorf("Sensitive match() 3rd: got %q", got)
+	}
+
+	// All invalid
+	value = "AAC*FDE*321"
+	if multiple.IsValid(value) {
+		t.Errorf("isValid() Invalid")
+	}
+	if _, ok := multiple.ValidateAndJoin(value); ok {
+		t.Errorf("validate() Invalid")
+	}
+	if got := multiple.Match(value); got != nil {
+		t.Errorf("match() Multiple: got %q", got)
+	}
+}
+
+/**
+ * Test with multiple regular expressions (case in-sensitive).
+ */
+func TestMultipleInsensitive(t *testing.T) {
+	multiple := MustNew(MULTIPLE_REGEX, CaseSensitive(false))
+	single1 := MustNew([]string{REGEX_1}, CaseSensitive(false))
+	single2 := MustNew([]string{REGEX_2}, CaseSensitive(false))
+	single3 := MustNew([]string{REGEX_3}, CaseSensitive(false))
+
+	// Valid
+	value := "AAC FDE 321"
+	expect := "AACFDE321"
+	array := []string{"AAC", "FDE", "321"}
+
+	// isValid()
+	if !multiple.IsValid(value) {
+		t.Errorf("isValid() Multiple")
+	}
+	if single1.IsValid(value) {
+		t.Errorf("isValid() 1st")
+	}
+	if !single2.IsValid(value) {
+		t.Errorf("isValid() 2nd")
+	}
+	if single3.IsValid(value) {
+		t.Errorf("isValid() 3rd")
+	}
+
+	// validate()
+	if s, ok := multiple.ValidateAndJoin(value); !ok || s != expect {
+		t.Errorf("validate() Multiple: got %q, %v", s, ok)
+	}
+	if s, ok := single2.ValidateAndJoin(value); !ok || s != expect {
+		t.Errorf("validate() 2nd: got %q, %v", s, ok)
+	}
+
+	// match()
+	if got := multiple.Match(value); !reflect.DeepEqual(got, array) {
+		t.Errorf("match() Multiple: got %q, want %q", got, array)
+	}
+	if got := single2.Match(value); !reflect.DeepEqual(got, array) {
+		t.Errorf("match() 2nd: got %q, want %q", got, array)
+	}
+}
+
+/**
+ * Test a value is matched as a whole, as Matcher.matches() does in Java.
+ */
+func TestWholeValue(t *testing.T) {
+	v := MustNew([]string{"[abc]+", "x|y"})
+	validValues := []string{"abc", "a", "x", "y"}
+	for _, value := range validValues {
+		if !v.IsValid(value) {
+			t.Errorf("expected valid value: %s", value)
+		}
+	}
+	invalidValues := []string{"", "abcd", "dabc", "xy", "ax", "x\n"}
+	for _, value := range invalidValues {
+		if v.IsValid(value) {
+			t.Errorf("expected invalid value: %q", value)
+		}
+	}
+
+	// No groups
+	if got := v.Match("abc"); got == nil || len(got) != 0 {
+		t.Errorf("expected no groups: got %q", got)
+	}
+	if s, ok := v.ValidateAndJoin("abc"); !ok || s != "" {
+		t.Errorf("expected empty join: got %q, %v", s, ok)
+	}
+
+	// A group that takes no part in the match is empty
+	optional := MustNew([]string{"(a)?(b)"})
+	if got, want := optional.Match("b"), []string{"", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("optional group: got %q, want %q", got, want)
+	}
+}
+
+/**
+ * Test exceptions for missing and invalid regular expressions.
+ */
+func TestMissingRegex(t *testing.T) {
+	if _, err := New(nil); err != ErrMissingPatterns {
+		t.Errorf("nil patterns: got %v", err)
+	}
+	if _, err := New([]string{}); err != ErrMissingPatterns {
+		t.Errorf("empty patterns: got %v", err)
+	}
+
+	_, err := New([]string{""})
+	var perr *PatternError
+	if !errors.As(err, &perr) || perr.Index != 0 || !errors.Is(err, ErrMissingPattern) {
+		t.Errorf("empty pattern: got %v", err)
+	}
+
+	_, err = New([]string{"ABC", ""})
+	if !errors.As(err, &perr) || perr.Index != 1 || !errors.Is(err, ErrMissingPattern) {
+		t.Errorf("empty 2nd pattern: got %v", err)
+	}
+	if got, want := err.Error(), "regexvalidator: regular expression[1]: regexvalidator: regular expression is missing"; got != want {
+		t.Errorf("error message: got %q, want %q", got, want)
+	}
+}
+
+/**
+ * Test invalid regular expressions.
+ */
+func TestExceptions(t *testing.T) {
+	invalidRegex := "^([abCD12]*$"
+	_, err := New([]string{REGEX, invalidRegex})
+	var perr *PatternError
+	if !errors.As(err, &perr) || perr.Index != 1 || errors.Is(err, ErrMissingPattern) {
+		t.Errorf("invalid regex: got %v", err)
+	}
+
+	// Unbalanced patterns must not escape the anchors added by New
+	for _, regex := range []string{"a)|(b", "a)(b", "(a))|((b"} {
+		_, err = New([]string{regex})
+		if !errors.As(err, &perr) || perr.Index != 0 {
+			t.Errorf("unbalanced regex %q: got %v", regex, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustNew to panic")
+		}
+	}()
+	MustNew([]string{invalidRegex})
+}
+
+/**
+ * Test toString() method.
+ */
+func TestToString(t *testing.T) {
+	single := MustNew([]string{REGEX})
+	if got, want := single.String(), "RegexValidator{"+REGEX+"}"; got != want {
+		t.Errorf("Single: got %q, want %q", got, want)
+	}
+
+	multiple := MustNew([]string{REGEX, REGEX})
+	if got, want := multiple.String(), "RegexValidator{"+REGEX+","+REGEX+"}"; got != want {
+		t.Errorf("Multiple: got %q, want %q", got, want)
+	}
+}
+
+/**
+ * Test the patterns are returned as given.
+ */
+func TestPatterns(t *testing.T) {
+	v := MustNew(MULTIPLE_REGEX, CaseSensitive(false))
+	patterns := v.Patterns()
+	if !reflect.DeepEqual(patterns, MULTIPLE_REGEX) {
+		t.Errorf("got %q, want %q", patterns, MULTIPLE_REGEX)
+	}
+	patterns[0] = "changed"
+	if v.Patterns()[0] != REGEX_1 {
+		t.Errorf("expected Patterns to return a copy")
+	}
+}
@@ -0,0 +1,379 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * http://svn.apache.org/viewvc/commons/proper/validator/trunk/src/main/java/org/apache/commons/validator/routines/UrlValidator.java?view=log
+ */
+package urlvalidator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dsparling/go-commons-validator/domainvalidator"
+	"github.com/dsparling/go-commons-validator/inetaddressvalidator"
+)
+
+const (
+	/**
+	 * Allows all validly formatted schemes to pass validation instead of
+	 * supplying a set of valid schemes.
+	 */
+	ALLOW_ALL_SCHEMES int64 = 1 << 0
+
+	/**
+	 * Allow two slashes in the path component of the URL.
+	 */
+	ALLOW_2_SLASHES int64 = 1 << 1
+
+	/**
+	 * Enabling this options disallows any URL fragments.
+	 */
+	NO_FRAGMENTS int64 = 1 << 2
+
+	/**
+	 * Allow local URLs, such as http://localhost/ or http://machine/ .
+	 * This enables a broad-brush check, for complex local machine name
+	 * validation requirements you should create your validator with
+	 * a suitable DomainValidator, see NewWithDomainValidator.
+	 */
+	ALLOW_LOCAL_URLS int64 = 1 << 3
+)
+
+const (
+	// Validation of character set is done by isValidAuthority
+	AUTHORITY_CHARS_REGEX = "a-zA-Z0-9\\-\\." // allows for IPV4 but not IPV6
+	IPV6_REGEX            = "[0-9a-fA-F:]+"   // do this as separate match because : could cause ambiguity with port prefix
+
+	// userinfo    = *( unreserved / pct-encoded / sub-delims / ":" )
+	// unreserved    = ALPHA / DIGIT / "-" / "." / "_" / "~"
+	// sub-delims    = "!" / "$" / "&" / "'" / "(" / ")" / "*" / "+" / "," / ";" / "="
+	// We assume that password has the same valid chars as user info
+	USERINFO_CHARS_REGEX = "[a-zA-Z0-9%\\-._~!$&'()*+,;=]"
+	// since neither ':' nor '@' are allowed chars, we don't need to use non-greedy matching
+	USERINFO_FIELD_REGEX = USERINFO_CHARS_REGEX + "+" + // At least one character for the name
+		"(?::" + USERINFO_CHARS_REGEX + "*)?@" // colon and password may be absent
+
+	/**
+	 * This expression derived/taken from the BNF for URI (RFC2396).
+	 */
+	URL_REGEX = "^(([^:/?#]+):)?(//([^/?#]*))?([^?#]*)(\\?([^#]*))?(#(.*))?$"
+	//            12            3  4          5       6   7        8 9
+
+	/**
+	 * Protocol scheme (e.g. http, ftp, https).
+	 */
+	SCHEME_REGEX = "^[a-zA-Z][a-zA-Z0-9+\\-.]*$"
+
+	// [ipv6] or [userinfo@]host, then an optional :port
+	AUTHORITY_REGEX = "^(?:\\[(" + IPV6_REGEX + ")\\]|(?:(?:" + USERINFO_FIELD_REGEX + ")?([" + AUTHORITY_CHARS_REGEX + "]*)))(?::(\\d*))?(.*)$"
+
+	PATH_REGEX        = "^(/[-\\w:@&?=+,.!/~*'%$_;\\(\\)]*)?$"
+	QUERY_REGEX       = "^(\\S*)$"
+	LEGAL_ASCII_REGEX = "^[\\x00-\\x7F]+$"
+
+	PARSE_URL_SCHEME    = 2
+	PARSE_URL_AUTHORITY = 4
+	PARSE_URL_PATH      = 5
+	PARSE_URL_QUERY     = 7
+	PARSE_URL_FRAGMENT  = 9
+
+	PARSE_AUTHORITY_IPV6    = 1
+	PARSE_AUTHORITY_HOST_IP = 2
+	PARSE_AUTHORITY_PORT    = 3
+	PARSE_AUTHORITY_EXTRA   = 4
+
+	MAX_UNSIGNED_16_BIT_INT = 0xFFFF // port max
+)
+
+/**
+ * If no schemes are provided, default to this set.
+ */
+var DEFAULT_SCHEMES = []string{"http", "https", "ftp"}
+
+var (
+	urlRegex        = regexp.MustCompile(URL_REGEX)
+	schemeRegex     = regexp.MustCompile(SCHEME_REGEX)
+	authorityRegex  = regexp.MustCompile(AUTHORITY_REGEX)
+	pathRegex       = regexp.MustCompile(PATH_REGEX)
+	queryRegex      = regexp.MustCompile(QUERY_REGEX)
+	legalAsciiRegex = regexp.MustCompile(LEGAL_ASCII_REGEX)
+)
+
+/**
+ * UrlValidator validates a URL against a set of allowed schemes and
+ * the option flags ALLOW_ALL_SCHEMES, ALLOW_2_SLASHES, NO_FRAGMENTS
+ * and ALLOW_LOCAL_URLS. Use New to create one.
+ */
+type UrlValidator struct {
+	options         int64
+	allowedSchemes  map[string]bool
+	domainValidator *domainvalidator.DomainValidator
+}
+
+/**
+ * New returns a UrlValidator. Schemes are compared case-insensitively;
+ * if schemes is empty, DEFAULT_SCHEMES is used. The schemes are ignored
+ * when ALLOW_ALL_SCHEMES is set.
+ * @param schemes The set of valid schemes.
+ * @param options The options should be set using the public constants
+ * declared in this package. To set multiple options you simply or them
+ * together: ALLOW_2_SLASHES | NO_FRAGMENTS
+ * @return the configured validator
+ */
+func New(schemes []string, options int64) *UrlValidator {
+	return NewWithDomainValidator(schemes, nil, options)
+}
+
+/**
+ * NewWithDomainValidator returns a UrlValidator that checks host names
+ * with domainValidator, so that its TLD overrides apply to URLs too.
+ * The validator is shared, not copied: later overrides apply as well.
+ * Local host names are then valid if domainValidator allows them,
+ * whether or not ALLOW_LOCAL_URLS is set.
+ * @param schemes The set of valid schemes, see New.
+ * @param domainValidator the validator to use, or nil for one created
+ * as by New
+ * @param options The options, see New.
+ * @return the configured validator
+ */
+func NewWithDomainValidator(schemes []string, domainValidator *domainvalidator.DomainValidator, options int64) *UrlValidator {
+	if domainValidator == nil {
+		domainValidator = domainvalidator.New(
+			domainvalidator.AllowLocal(options&ALLOW_LOCAL_URLS != 0))
+	}
+	v := &UrlValidator{
+		options:         options,
+		allowedSchemes:  make(map[string]bool),
+		domainValidator: domainValidator,
+	}
+	if v.isOn(ALLOW_ALL_SCHEMES) {
+		return v
+	}
+	if len(schemes) == 0 {
+		schemes = DEFAULT_SCHEMES
+	}
+	for _, scheme := range schemes {
+		v.allowedSchemes[strings.ToLower(scheme)] = true
+	}
+	return v
+}
+
+var defaultValidator = New(nil, 0)
+
+/**
+ * Checks if a field has a valid url address, using the default
+ * validator which allows the http, https and ftp schemes.
+ * @param value The value validation is being performed on.
+ * @return true if the url is valid.
+ */
+func IsValid(value string) bool {
+	return defaultValidator.IsValid(value)
+}
+
+/**
+ * Checks if a field has a valid url address.
+ * @param value The value validation is being performed on.
+ * @return true if the url is valid.
+ */
+func (v *UrlValidator) IsValid(value string) bool {
+	if !legalAsciiRegex.MatchString(value) {
+		return false
+	}
+
+	// Check the whole url address structure
+	groups := submatches(urlRegex, value)
+	if groups == nil {
+		return false
+	}
+
+	scheme := groups[PARSE_URL_SCHEME]
+	if scheme == nil || !v.isValidScheme(*scheme) {
+		return false
+	}
+
+	authority := groups[PARSE_URL_AUTHORITY]
+	if *scheme == "file" && (authority == nil || *authority == "") {
+		// Special case - file: allows an empty authority
+	} else if authority == nil || !v.isValidAuthority(*authority) {
+		return false
+	}
+
+	if !v.isValidPath(*groups[PARSE_URL_PATH]) {
+		return false
+	}
+
+	if !v.isValidQuery(groups[PARSE_URL_QUERY]) {
+		return false
+	}
+
+	if !v.isValidFragment(groups[PARSE_URL_FRAGMENT]) {
+		return false
+	}
+
+	return true
+}
+
+/**
+ * Validate scheme. If schemes were supplied to New, only those are
+ * allowed.
+ * @param scheme The scheme to validate.
+ * @return true if valid.
+ */
+func (v *UrlValidator) isValidScheme(scheme string) bool {
+	if !schemeRegex.MatchString(scheme) {
+		return false
+	}
+
+	if v.isOff(ALLOW_ALL_SCHEMES) && !v.allowedSchemes[strings.ToLower(scheme)] {
+		return false
+	}
+
+	return true
+}
+
+/**
+ * Returns true if the authority is properly formatted. An authority is
+ * the combination of hostname and port. A null authority value is
+ * considered invalid.
+ * @param authority Authority value to validate
+ * @return true if authority (hostname and port) is valid.
+ */
+func (v *UrlValidator) isValidAuthority(authority string) bool {
+	groups := submatches(authorityRegex, authority)
+	if groups == nil {
+		return false
+	}
+
+	// We have to process IPV6 separately because that is parsed in a different group
+	if ipv6 := groups[PARSE_AUTHORITY_IPV6]; ipv6 != nil {
+		if !inetaddressvalidator.IsValidInet6Address(*ipv6) {
+			return false
+		}
+	} else {
+		hostLocation := *groups[PARSE_AUTHORITY_HOST_IP]
+		// check if authority is hostname or IP address:
+		// try a hostname first since that's much more likely
+		if !v.domainValidator.IsValid(hostLocation) &&
+			!inetaddressvalidator.IsValidInet4Address(hostLocation) {
+			// isn't IPv4, so the URL is invalid
+			return false
+		}
+	}
+
+	if port := groups[PARSE_AUTHORITY_PORT]; port != nil && *port != "" {
+		iPort, err := strconv.Atoi(*port)
+		if err != nil || iPort < 0 || iPort > MAX_UNSIGNED_16_BIT_INT {
+			return false
+		}
+	}
+
+	if extra := groups[PARSE_AUTHORITY_EXTRA]; extra != nil && strings.TrimSpace(*extra) != "" {
+		return false
+	}
+
+	return true
+}
+
+/**
+ * Returns true if the path is valid. A null value is considered invalid.
+ * @param path Path value to validate.
+ * @return true if path is valid.
+ */
+func (v *UrlValidator) isValidPath(path string) bool {
+	if !pathRegex.MatchString(path) {
+		return false
+	}
+
+	// Don't allow the path to climb above the root, e.g. /../etc
+	depth := 0
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			depth--
+			if depth < 0 {
+				return false
+			}
+		default:
+			depth++
+		}
+	}
+
+	if v.isOff(ALLOW_2_SLASHES) && strings.Contains(path, "//") {
+		return false
+	}
+
+	return true
+}
+
+/**
+ * Returns true if the query is null or it's a properly formatted query string.
+ * @param query Query value to validate.
+ * @return true if query is valid.
+ */
+func (v *UrlValidator) isValidQuery(query *string) bool {
+	if query == nil {
+		return true
+	}
+
+	return queryRegex.MatchString(*query)
+}
+
+/**
+ * Returns true if the given fragment is null or fragments are allowed.
+ * @param fragment Fragment value to validate.
+ * @return true if fragment is valid.
+ */
+func (v *UrlValidator) isValidFragment(fragment *string) bool {
+	if fragment == nil {
+		return true
+	}
+
+	return v.isOff(NO_FRAGMENTS)
+}
+
+/**
+ * Tests whether the given flag is on.
+ */
+func (v *UrlValidator) isOn(flag int64) bool {
+	return v.options&flag > 0
+}
+
+/**
+ * Tests whether the given flag is off.
+ */
+func (v *UrlValidator) isOff(flag int64) bool {
+	return v.options&flag == 0
+}
+
+// submatches returns the groups of the leftmost match of r in s, with a
+// nil entry for each group that did not participate in the match, or
+// nil if there is no match.
+func submatches(r *regexp.Regexp, s string) []*string {
+	loc := r.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil
+	}
+	groups := make([]*string, len(loc)/2)
+	for i := range groups {
+		if loc[2*i] >= 0 {
+			group := s[loc[2*i]:loc[2*i+1]]
+			groups[i] = &group
+		}
+	}
+	return groups
+}